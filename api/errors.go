@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+)
+
+// QueryError is a statement of a query that did not succeed. The message is
+// exactly what SurrealDB reported.
+type QueryError struct {
+	Statement int    // Index of the statement within the query
+	Message   string // SurrealDB's message
+	Time      string // Execution time as reported, i.e. "1.2ms"
+}
+
+var _ (error) = (*QueryError)(nil)
+
+func (e *QueryError) Error() string {
+	return e.Message
+}
+
+// ErrorClass is a coarse category of an error, suitable for metrics and
+// tracing.
+type ErrorClass string
+
+const (
+	ErrorClassNone        ErrorClass = ""
+	ErrorClassParse       ErrorClass = "parse"       // Invalid SurrealQL or request
+	ErrorClassAuth        ErrorClass = "auth"        // Signing in or the token failed
+	ErrorClassPermission  ErrorClass = "permission"  // Not allowed to do that
+	ErrorClassConflict    ErrorClass = "conflict"    // Record already exists, or a write conflict
+	ErrorClassValidation  ErrorClass = "validation"  // Schema, type or assertion mismatch
+	ErrorClassNotFound    ErrorClass = "not_found"   // Namespace, database, table, ... does not exist
+	ErrorClassThrown      ErrorClass = "thrown"      // THROW statement
+	ErrorClassTransaction ErrorClass = "transaction" // Statement skipped because the transaction failed
+	ErrorClassTimeout     ErrorClass = "timeout"     // Deadline exceeded, client or server side
+	ErrorClassCanceled    ErrorClass = "canceled"    // Context was cancelled
+	ErrorClassConnection  ErrorClass = "connection"  // Connection unusable
+	ErrorClassRPC         ErrorClass = "rpc"         // Any other RPC level error
+	ErrorClassUnknown     ErrorClass = "unknown"
+)
+
+// SurrealDB's messages, lowercased, mapped to their class. The first match
+// wins, so more specific phrases come first.
+var messageClasses = []struct {
+	phrase string
+	class  ErrorClass
+}{
+	{"an error occurred: ", ErrorClassThrown},
+	{"failed transaction", ErrorClassTransaction},
+	{"parse error", ErrorClassParse},
+	{"failed to parse", ErrorClassParse},
+	{"there was a problem with authentication", ErrorClassAuth},
+	{"token", ErrorClassAuth},
+	{"not enough permissions", ErrorClassPermission},
+	{"not allowed", ErrorClassPermission},
+	{"already exists", ErrorClassConflict},
+	{"conflict", ErrorClassConflict},
+	{"but expected", ErrorClassValidation},
+	{"assertion", ErrorClassValidation},
+	{"couldn't coerce", ErrorClassValidation},
+	{"does not exist", ErrorClassNotFound},
+	{"not found", ErrorClassNotFound},
+	{"timeout", ErrorClassTimeout},
+	{"timed out", ErrorClassTimeout},
+}
+
+// Classify sorts an error returned by the driver into an ErrorClass. Joined
+// errors are classified by their first part.
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassNone
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		if errs := joined.Unwrap(); len(errs) > 0 {
+			return Classify(errs[0])
+		}
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, driver.ErrBadConn):
+		return ErrorClassConnection
+	}
+
+	var qe *QueryError
+	if errors.As(err, &qe) {
+		return classifyMessage(qe.Message)
+	}
+	var ae *APIError
+	if errors.As(err, &ae) {
+		switch ae.Code {
+		case -32700, -32600:
+			return ErrorClassParse
+		}
+		if class := classifyMessage(ae.Message); class != ErrorClassUnknown {
+			return class
+		}
+		return ErrorClassRPC
+	}
+	return ErrorClassUnknown
+}
+
+func classifyMessage(msg string) ErrorClass {
+	msg = strings.ToLower(msg)
+	for _, mc := range messageClasses {
+		if strings.Contains(msg, mc.phrase) {
+			return mc.class
+		}
+	}
+	return ErrorClassUnknown
+}
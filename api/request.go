@@ -4,6 +4,8 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"strconv"
+	"sync/atomic"
 
 	"github.com/IngwiePhoenix/surrealdb-driver/config"
 	"github.com/wI2L/jsondiff"
@@ -28,6 +30,7 @@ type Request struct {
 
 type SurrealCaller struct {
 	ConnID RequestID
+	seq    atomic.Uint64
 }
 
 func MakeCaller() *SurrealCaller {
@@ -36,9 +39,15 @@ func MakeCaller() *SurrealCaller {
 	}
 }
 
+// Every request gets its own ID, so that responses can be matched up with
+// their requests even when several of them are in flight.
+func (c *SurrealCaller) nextID() RequestID {
+	return c.ConnID + "-" + strconv.FormatUint(c.seq.Add(1), 10)
+}
+
 func (c *SurrealCaller) CallVersion() *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "version",
 		Params: nil,
 	}
@@ -57,14 +66,14 @@ func (c *SurrealCaller) CallUse(ns string, db string) *Request {
 		params = append(params, db)
 	}
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "use",
 		Params: params,
 	}
 }
 func (c *SurrealCaller) CallInfo() *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "info",
 		Params: nil,
 	}
@@ -84,7 +93,7 @@ func (c *SurrealCaller) CallSignup(
 		params[k] = v
 	}
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "signup",
 		Params: []any{params},
 	}
@@ -96,8 +105,14 @@ func (c *SurrealCaller) CallSignin(creds *config.Credentials) (*Request, error)
 		params["user"] = creds.Username
 		params["pass"] = creds.Password
 	case config.AuthMethodRecord:
-		params["user"] = creds.Username
-		params["pass"] = creds.Password
+		// Record access defines its own signin variables; user and pass are
+		// merely the common case.
+		if creds.Username != "" {
+			params["user"] = creds.Username
+		}
+		if creds.Password != "" {
+			params["pass"] = creds.Password
+		}
 		params["NS"] = creds.Namespace
 		params["DB"] = creds.Database
 		params["AC"] = creds.AccessControl
@@ -123,48 +138,48 @@ func (c *SurrealCaller) CallSignin(creds *config.Credentials) (*Request, error)
 	}
 
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "signin",
 		Params: []any{params},
 	}, nil
 }
 func (c *SurrealCaller) CallAuthenticate(token string) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "authenticate",
 		Params: []string{token},
 	}
 }
 func (c *SurrealCaller) CallInvalidate() *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "invalidate",
 	}
 }
 func (c *SurrealCaller) CallLet(key string, value any) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "let",
 		Params: []any{key, value},
 	}
 }
 func (c *SurrealCaller) CallUnset(key string) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "unset",
 		Params: []string{key},
 	}
 }
 func (c *SurrealCaller) CallLive(table string, diff bool) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "live",
 		Params: []any{table, diff},
 	}
 }
 func (c *SurrealCaller) CallKill(queryUuid string) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "kill",
 		Params: []string{queryUuid},
 	}
@@ -174,7 +189,7 @@ func (c *SurrealCaller) CallQuery(
 	vars map[string]interface{},
 ) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "query",
 		Params: []any{sql, vars},
 	}
@@ -187,7 +202,7 @@ func (c *SurrealCaller) CallRun(
 	var params []interface{}
 	params = append(params, version, args)
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "run",
 		Params: params,
 	}
@@ -208,28 +223,28 @@ func (c *SurrealCaller) CallGraphQL(
 		params = append(params, query)
 	}
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "graphql",
 		Params: params,
 	}
 }
 func (c *SurrealCaller) CallSelect(thing string) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "select",
 		Params: []string{thing},
 	}
 }
 func (c *SurrealCaller) CallCreate(thing string, data interface{}) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "create",
 		Params: []any{thing, data},
 	}
 }
 func (c *SurrealCaller) CallInsert(thing string, data any) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "insert",
 		Params: []any{thing, data},
 	}
@@ -246,21 +261,21 @@ func (c *SurrealCaller) CallInsertRelation(
 		params = append(params, data)
 	}
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "insert",
 		Params: params,
 	}
 }
 func (c *SurrealCaller) CallUpdate(thing string, data interface{}) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "update",
 		Params: []any{thing, data},
 	}
 }
 func (c *SurrealCaller) CallUpsert(thing string, data interface{}) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "upsert",
 		Params: []any{thing, data},
 	}
@@ -272,28 +287,28 @@ func (c *SurrealCaller) CallRelate(
 	data interface{},
 ) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "relate",
 		Params: []any{in, relation, out, data},
 	}
 }
 func (c *SurrealCaller) CallMerge(thing string, data interface{}) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "merge",
 		Params: []any{thing, data},
 	}
 }
 func (c *SurrealCaller) CallPatch(thing string, patches jsondiff.Patch, diff bool) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "merge",
 		Params: []any{thing, patches, diff},
 	}
 }
 func (c *SurrealCaller) CallDelete(thing string) *Request {
 	return &Request{
-		ID:     c.ConnID,
+		ID:     c.nextID(),
 		Method: "delete",
 		Params: []string{thing},
 	}
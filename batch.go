@@ -0,0 +1,198 @@
+package surrealdbdriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/IngwiePhoenix/surrealdb-driver/api"
+	"github.com/tidwall/gjson"
+)
+
+// Batch queues statements to be sent together in a single `query` RPC:
+//
+//	b := &surrealdbdriver.Batch{}
+//	b.Queue("CREATE person SET name = $name", map[string]any{"name": "Tobie"})
+//	b.Queue("SELECT * FROM person WHERE name = $name", map[string]any{"name": "Jaime"})
+//	results, err := con.SendBatch(ctx, b)
+//
+// Every queued query must be a single statement. Its variables only apply to
+// it; they are renamed before sending, so that equally named variables of
+// different statements do not collide.
+//
+// With database/sql, the connection is reached through sql.Conn.Raw.
+type Batch struct {
+	// Wraps the statements in BEGIN and COMMIT, so that either all of them
+	// take effect or none does.
+	Transaction bool
+
+	queued []*QueuedQuery
+}
+
+// QueuedQuery is a statement in a Batch.
+type QueuedQuery struct {
+	SQL  string
+	Vars map[string]any
+}
+
+// Queue adds a statement with its variables, referenced as $name in sql.
+// sql must hold exactly one statement: results are matched to queued
+// queries by position. A trailing ';' or comment is fine.
+func (b *Batch) Queue(sql string, vars map[string]any) *QueuedQuery {
+	q := &QueuedQuery{SQL: sql, Vars: vars}
+	b.queued = append(b.queued, q)
+	return q
+}
+
+// Len is the number of queued statements.
+func (b *Batch) Len() int {
+	return len(b.queued)
+}
+
+// Builds the query text and variables sent for the batch.
+func (b *Batch) build() (string, map[string]any) {
+	sql := strings.Builder{}
+	vars := map[string]any{}
+	if b.Transaction {
+		sql.WriteString("BEGIN TRANSACTION;\n")
+	}
+	for i, q := range b.queued {
+		prefix := "_batch" + strconv.Itoa(i) + "_"
+		rename := make(map[string]string, len(q.Vars))
+		for name, v := range q.Vars {
+			rename[name] = prefix + name
+			vars[prefix+name] = v
+		}
+		stmt := strings.TrimRight(strings.TrimSpace(renameVars(q.SQL, rename)), ";")
+		// The ';' gets a line of its own, or a trailing -- comment would
+		// swallow it.
+		sql.WriteString(stmt)
+		sql.WriteString("\n;\n")
+	}
+	if b.Transaction {
+		sql.WriteString("COMMIT TRANSACTION;\n")
+	}
+	return sql.String(), vars
+}
+
+// SendBatch sends all queued statements in one round-trip. The returned
+// error is only set if the batch could not be sent at all; the outcome of
+// each statement is read from the BatchResults.
+func (con *SurrealConn) SendBatch(ctx context.Context, b *Batch) (*BatchResults, error) {
+	if b.Len() == 0 {
+		return &BatchResults{}, nil
+	}
+	sql, vars := bindParams(b.build())
+	res, err := con.execObj(ctx, con.Caller.CallQuery(sql, vars))
+	con.noteSchemaChange(sql)
+	if res == nil {
+		return nil, err
+	}
+
+	statements := res.Result.Array()
+	if b.Transaction && len(statements) == b.Len()+2 {
+		// Older servers answer BEGIN and COMMIT, too.
+		statements = statements[1 : len(statements)-1]
+	}
+	if len(statements) != b.Len() {
+		return nil, fmt.Errorf("batch of %d statements got %d results; was a queued query more than one statement?", b.Len(), len(statements))
+	}
+	return &BatchResults{
+		conn:       con,
+		statements: statements,
+		schemas:    con.loadSchemas(ctx, statements),
+	}, nil
+}
+
+// BatchResults yields the results of a batch's statements in the order they
+// were queued. Each call to Exec, Query or Result consumes one statement.
+type BatchResults struct {
+	conn       *SurrealConn
+	statements []gjson.Result
+	next       int
+	schemas    map[string]tableSchema // For DecodeSchema
+}
+
+// Len is the number of statements.
+func (br *BatchResults) Len() int {
+	return len(br.statements)
+}
+
+// Result returns the next statement's raw result, or its error as an
+// *api.QueryError.
+func (br *BatchResults) Result() (gjson.Result, error) {
+	if br.next >= len(br.statements) {
+		return gjson.Result{}, fmt.Errorf("batch has only %d statements", len(br.statements))
+	}
+	idx := br.next
+	stmt := br.statements[idx]
+	br.next++
+	if stmt.Get("status").String() != "OK" {
+		return stmt.Get("result"), &api.QueryError{
+			Statement: idx,
+			Message:   stmt.Get("result").String(),
+			Time:      stmt.Get("time").String(),
+		}
+	}
+	return stmt.Get("result"), nil
+}
+
+// Exec returns the next statement's outcome as a driver.Result.
+func (br *BatchResults) Exec() (driver.Result, error) {
+	res, err := br.response()
+	if err != nil {
+		return nil, err
+	}
+	return &SurrealResult{RawResult: res}, nil
+}
+
+// Query returns the next statement's records as driver.Rows.
+func (br *BatchResults) Query() (driver.Rows, error) {
+	res, err := br.response()
+	if err != nil {
+		return nil, err
+	}
+	return &SurrealRows{
+		conn:      br.conn,
+		RawResult: res,
+		decoding:  br.conn.decoding(),
+		schemas:   br.schemas,
+	}, nil
+}
+
+// Close returns the error of the first statement not read yet that failed.
+func (br *BatchResults) Close() error {
+	for br.next < len(br.statements) {
+		if _, err := br.Result(); err != nil {
+			br.next = len(br.statements)
+			return err
+		}
+	}
+	return nil
+}
+
+// The next statement, shaped like the response to a query of its own.
+func (br *BatchResults) response() (*api.Response, error) {
+	idx := br.next
+	if _, err := br.Result(); err != nil {
+		return nil, err
+	}
+	return &api.Response{
+		Method: api.APIMethodQuery,
+		Result: gjson.Parse("[" + br.statements[idx].Raw + "]"),
+	}, nil
+}
+
+// Renames the $variables in sql, leaving strings, escaped identifiers and
+// comments alone.
+func renameVars(sql string, rename map[string]string) string {
+	if len(rename) == 0 {
+		return sql
+	}
+	return rewriteVars(sql, func(name string) (string, bool) {
+		to, ok := rename[name]
+		return "$" + to, ok
+	})
+}
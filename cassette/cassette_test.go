@@ -0,0 +1,121 @@
+package cassette_test
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+
+	surrealdbdriver "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/IngwiePhoenix/surrealdb-driver/cassette"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealtest"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	srv.OnQuery("SELECT * FROM person").Return([]map[string]any{{"id": "person:tobie", "name": "Tobie"}})
+	srv.OnQuery("RETURN 1").Return([]map[string]any{{"one": 1}})
+
+	var tape bytes.Buffer
+	rec := cassette.NewRecorder(&tape)
+	connector, err := surrealdbdriver.NewConnector(
+		surrealdbdriver.WithDSN(srv.DSN()),
+		surrealdbdriver.WithTransportWrapper(rec.Wrap),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	names := queryNames(t, db)
+	db.Close()
+	if rec.Err() != nil {
+		t.Fatal(rec.Err())
+	}
+	if strings.Contains(tape.String(), `"root"`) || strings.Contains(tape.String(), "surrealtest-token") {
+		t.Errorf("secrets were recorded:\n%s", tape.String())
+	}
+
+	replay := func(t *testing.T, rep *cassette.Replayer) *sql.DB {
+		connector, err := surrealdbdriver.NewConnector(
+			surrealdbdriver.WithDSN(srv.DSN()),
+			surrealdbdriver.WithTransport(rep.Dial),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sql.OpenDB(connector)
+	}
+
+	t.Run("InOrder", func(t *testing.T) {
+		rep, err := cassette.Load(bytes.NewReader(tape.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv.Reset()
+		db := replay(t, rep)
+		defer db.Close()
+		if got := queryNames(t, db); got != names {
+			t.Errorf("replayed %q, recorded %q", got, names)
+		}
+		if len(srv.Requests()) != 0 {
+			t.Error("replaying talked to the server")
+		}
+	})
+
+	t.Run("Matching", func(t *testing.T) {
+		rep, err := cassette.Load(bytes.NewReader(tape.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep.Mode = cassette.Matching
+		db := replay(t, rep)
+		defer db.Close()
+		var one int
+		if err := db.QueryRow("RETURN 1").Scan(&one); err != nil || one != 1 {
+			t.Errorf("got %d, %v", one, err)
+		}
+		if _, err := db.Exec("RETURN 2"); err == nil {
+			t.Error("unrecorded query succeeded")
+		}
+		if len(rep.Unexpected()) != 1 {
+			t.Errorf("unexpected requests: %v", rep.Unexpected())
+		}
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		rep, err := cassette.Load(bytes.NewReader(tape.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep.Mode = cassette.Matching
+		rep.Strict = true
+		db := replay(t, rep)
+		defer db.Close()
+		db.SetMaxOpenConns(1)
+		if _, err := db.Exec("RETURN 2"); err == nil {
+			t.Error("unrecorded query succeeded")
+		}
+	})
+}
+
+func queryNames(t *testing.T, db *sql.DB) string {
+	t.Helper()
+	rows, err := db.Query("SELECT * FROM person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, id+"="+name)
+	}
+	var one int
+	if err := db.QueryRow("RETURN 1").Scan(&one); err != nil {
+		t.Fatal(err)
+	}
+	return strings.Join(out, ",")
+}
@@ -0,0 +1,147 @@
+/*
+Package cassette records the RPC traffic between the driver and a real
+SurrealDB into a JSONL file, and replays it later without a server.
+
+Recording wraps the usual WebSocket transport:
+
+	rec := cassette.NewRecorder(file)
+	connector, _ := surrealdbdriver.NewConnector(
+		surrealdbdriver.WithDSN(dsn),
+		surrealdbdriver.WithTransportWrapper(rec.Wrap),
+	)
+
+Replaying replaces it:
+
+	rep, _ := cassette.LoadFile("testdata/users.jsonl")
+	connector, _ := surrealdbdriver.NewConnector(
+		surrealdbdriver.WithDSN(dsn),
+		surrealdbdriver.WithTransport(rep.Dial),
+	)
+
+Each line of a cassette is one Frame. Credentials and tokens of signin,
+signup and authenticate are redacted while recording.
+*/
+package cassette
+
+import (
+	"io"
+	"sync"
+
+	surrealdbdriver "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/goccy/go-json"
+	"github.com/tidwall/gjson"
+)
+
+// What a frame in a cassette is.
+const (
+	KindRequest      = "request"
+	KindResponse     = "response"
+	KindNotification = "notification"
+)
+
+const redacted = `"[REDACTED]"`
+
+// Frame is one line of a cassette.
+type Frame struct {
+	Kind  string          `json:"kind"`
+	Frame json.RawMessage `json:"frame"`
+}
+
+// Methods whose parameters and results are secrets.
+var secretMethods = map[string]bool{"signin": true, "signup": true, "authenticate": true}
+
+// Recorder writes every frame passing through the transports it wraps to w.
+// It is safe to share between connections.
+type Recorder struct {
+	mu      sync.Mutex
+	enc     *json.Encoder
+	err     error
+	methods map[string]string // Request ID -> method, to redact responses
+}
+
+// NewRecorder records into w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w), methods: map[string]string{}}
+}
+
+// Wrap is meant for surrealdbdriver.WithTransportWrapper.
+func (r *Recorder) Wrap(t surrealdbdriver.Transport) surrealdbdriver.Transport {
+	return &recording{Transport: t, rec: r}
+}
+
+// Err returns the first error that happened while writing the cassette.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Recorder) request(msg []byte) {
+	parsed := gjson.ParseBytes(msg)
+	method := parsed.Get("method").String()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if secretMethods[method] {
+		r.methods[parsed.Get("id").String()] = method
+		msg = replace(msg, "params", redacted)
+	}
+	r.write(KindRequest, msg)
+}
+
+func (r *Recorder) response(msg []byte) {
+	parsed := gjson.ParseBytes(msg)
+	kind := KindResponse
+	if !parsed.Get("id").Exists() {
+		kind = KindNotification
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := parsed.Get("id").String()
+	if _, ok := r.methods[id]; ok {
+		delete(r.methods, id)
+		if parsed.Get("result").Exists() {
+			msg = replace(msg, "result", redacted)
+		}
+	}
+	r.write(kind, msg)
+}
+
+// Must be called with mu held.
+func (r *Recorder) write(kind string, msg []byte) {
+	if r.err != nil {
+		return
+	}
+	r.err = r.enc.Encode(Frame{Kind: kind, Frame: msg})
+}
+
+type recording struct {
+	surrealdbdriver.Transport
+	rec *Recorder
+}
+
+func (t *recording) WriteMessage(msg []byte) error {
+	t.rec.request(msg)
+	return t.Transport.WriteMessage(msg)
+}
+
+func (t *recording) ReadMessage() ([]byte, error) {
+	msg, err := t.Transport.ReadMessage()
+	if err == nil {
+		t.rec.response(msg)
+	}
+	return msg, err
+}
+
+// Returns msg with one top level key set to raw JSON.
+func replace(msg []byte, key string, raw string) []byte {
+	obj := map[string]json.RawMessage{}
+	if err := json.Unmarshal(msg, &obj); err != nil {
+		return msg
+	}
+	obj[key] = json.RawMessage(raw)
+	out, err := json.Marshal(obj)
+	if err != nil {
+		return msg
+	}
+	return out
+}
@@ -0,0 +1,218 @@
+package cassette
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+
+	surrealdbdriver "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/goccy/go-json"
+	"github.com/tidwall/gjson"
+)
+
+// Mode decides which recorded response answers a request.
+type Mode int
+
+const (
+	// InOrder answers with the next recorded response, as long as the
+	// methods match.
+	InOrder Mode = iota
+	// Matching answers with the first unused response whose request had the
+	// same method and parameters. Request IDs are ignored.
+	Matching
+)
+
+// One recorded request with everything it caused.
+type interaction struct {
+	method        string
+	params        gjson.Result
+	response      []byte
+	notifications [][]byte
+	used          bool
+}
+
+// Replayer serves a cassette. It is safe to share between connections.
+type Replayer struct {
+	// Mode defaults to InOrder.
+	Mode Mode
+	// Strict breaks the connection on a request the cassette has no response
+	// for. Otherwise, such a request is answered with an RPC error.
+	Strict bool
+
+	mu           sync.Mutex
+	interactions []*interaction
+	unexpected   []string
+}
+
+// LoadFile reads a cassette from a file.
+func LoadFile(path string) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Load reads a cassette.
+func Load(r io.Reader) (*Replayer, error) {
+	rep := &Replayer{}
+	byID := map[string]*interaction{}
+	var last *interaction // The interaction whose response came last
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var f Frame
+		if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			return nil, fmt.Errorf("cassette: line %d: %w", line, err)
+		}
+		msg := gjson.ParseBytes(f.Frame)
+		switch f.Kind {
+		case KindRequest:
+			i := &interaction{method: msg.Get("method").String(), params: msg.Get("params")}
+			byID[msg.Get("id").String()] = i
+			rep.interactions = append(rep.interactions, i)
+		case KindResponse:
+			i, ok := byID[msg.Get("id").String()]
+			if !ok {
+				return nil, fmt.Errorf("cassette: line %d: response to an unknown request", line)
+			}
+			i.response = f.Frame
+			last = i
+		case KindNotification:
+			if last != nil {
+				last.notifications = append(last.notifications, f.Frame)
+			}
+		default:
+			return nil, fmt.Errorf("cassette: line %d: unknown kind %q", line, f.Kind)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rep, nil
+}
+
+// Dial is meant for surrealdbdriver.WithTransport.
+func (r *Replayer) Dial(ctx context.Context) (surrealdbdriver.Transport, error) {
+	return &replaying{
+		rep:    r,
+		out:    make(chan []byte, 64),
+		closed: make(chan struct{}),
+	}, nil
+}
+
+// Unused returns the methods of recorded requests nobody sent, in order.
+func (r *Replayer) Unused() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := []string{}
+	for _, i := range r.interactions {
+		if !i.used {
+			out = append(out, i.method)
+		}
+	}
+	return out
+}
+
+// Unexpected describes the requests the cassette had no response for.
+func (r *Replayer) Unexpected() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.unexpected...)
+}
+
+// Finds and consumes the interaction answering a request.
+func (r *Replayer) take(method string, params gjson.Result) *interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, i := range r.interactions {
+		if i.used || i.response == nil {
+			continue
+		}
+		if r.Mode == InOrder {
+			if i.method != method {
+				break
+			}
+		} else if i.method != method || !sameParams(method, i.params, params) {
+			continue
+		}
+		i.used = true
+		return i
+	}
+	r.unexpected = append(r.unexpected, method+" "+params.Raw)
+	return nil
+}
+
+func sameParams(method string, a, b gjson.Result) bool {
+	if secretMethods[method] {
+		// Redacted while recording.
+		return true
+	}
+	return reflect.DeepEqual(a.Value(), b.Value())
+}
+
+type replaying struct {
+	rep       *Replayer
+	out       chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (t *replaying) WriteMessage(msg []byte) error {
+	req := gjson.ParseBytes(msg)
+	id := req.Get("id").String()
+	method := req.Get("method").String()
+	i := t.rep.take(method, req.Get("params"))
+	if i == nil {
+		if t.rep.Strict {
+			return errors.New("cassette: unexpected request: " + method + " " + req.Get("params").Raw)
+		}
+		return t.send([]byte(`{"id":` + strconv.Quote(id) + `,"error":{"code":-32000,"message":` +
+			strconv.Quote("cassette: no recorded response for "+method) + `}}`))
+	}
+	if err := t.send(replace(i.response, "id", strconv.Quote(id))); err != nil {
+		return err
+	}
+	for _, n := range i.notifications {
+		if err := t.send(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *replaying) send(msg []byte) error {
+	select {
+	case t.out <- msg:
+		return nil
+	case <-t.closed:
+		return errors.New("cassette: transport closed")
+	}
+}
+
+func (t *replaying) ReadMessage() ([]byte, error) {
+	select {
+	case msg := <-t.out:
+		return msg, nil
+	case <-t.closed:
+		return nil, io.EOF
+	}
+}
+
+func (t *replaying) Close() error {
+	t.closeOnce.Do(func() { close(t.closed) })
+	return nil
+}
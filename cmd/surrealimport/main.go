@@ -0,0 +1,136 @@
+// Command surrealimport loads CSV or JSON Lines into a SurrealDB table.
+//
+//	surrealimport -dsn 'ws://root:root@localhost:8000/rpc?method=root&ns=app&db=app' \
+//		-table person -id username -cast age=int,active=bool people.csv
+//
+// The format is guessed from the file's extension unless -format is given;
+// without a file, stdin is read. Progress goes to stderr. If a batch fails,
+// the offset to resume from is printed; pass it back via -offset.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	_ "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealimport"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "surrealimport:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		dsn       = flag.String("dsn", os.Getenv("SURREALDB_DSN"), "DSN to connect to; defaults to $SURREALDB_DSN")
+		table     = flag.String("table", "", "table to import into (required)")
+		format    = flag.String("format", "", "csv or jsonl; guessed from the file name by default")
+		id        = flag.String("id", "", "field holding the record ID")
+		casts     = flag.String("cast", "", "comma separated field=cast pairs; casts are string, int, float, bool, json, null")
+		rename    = flag.String("rename", "", "comma separated from=to pairs")
+		only      = flag.Bool("only-renamed", false, "drop fields not mentioned in -rename")
+		comma     = flag.String("comma", ",", "CSV field delimiter")
+		batchSize = flag.Int("batch", surrealimport.DefaultBatchSize, "records per batch")
+		offset    = flag.Int64("offset", 0, "skip this many records, i.e. to resume")
+		useQuery  = flag.Bool("query", false, "write with INSERT INTO instead of the insert RPC")
+		keepGoing = flag.Bool("continue", false, "keep going when a batch fails")
+		quiet     = flag.Bool("quiet", false, "do not report progress")
+	)
+	flag.Parse()
+	if *dsn == "" || *table == "" || flag.NArg() > 1 {
+		flag.Usage()
+		return fmt.Errorf("need -dsn, -table and at most one file")
+	}
+
+	cfg := surrealimport.Config{
+		Table:           *table,
+		IDField:         *id,
+		OnlyRenamed:     *only,
+		BatchSize:       *batchSize,
+		Offset:          *offset,
+		UseQuery:        *useQuery,
+		ContinueOnError: *keepGoing,
+		Casts:           map[string]surrealimport.Cast{},
+		Rename:          map[string]string{},
+	}
+	if r := []rune(*comma); len(r) == 1 {
+		cfg.Comma = r[0]
+	} else {
+		return fmt.Errorf("-comma must be a single character")
+	}
+	for field, name := range pairs(*casts) {
+		cast, err := surrealimport.ParseCast(name)
+		if err != nil {
+			return err
+		}
+		cfg.Casts[field] = cast
+	}
+	for from, to := range pairs(*rename) {
+		cfg.Rename[from] = to
+	}
+	if !*quiet {
+		cfg.OnProgress = func(p surrealimport.Progress) {
+			if p.Err != nil {
+				fmt.Fprintf(os.Stderr, "batch %d failed: %s\n", p.Batch, p.Err)
+			}
+			fmt.Fprintf(os.Stderr, "batch %d: %d records, %d imported, %d failed\n", p.Batch, p.Offset, p.Imported, p.Failed)
+		}
+	}
+
+	var in io.Reader = os.Stdin
+	name := "-"
+	if flag.NArg() == 1 && flag.Arg(0) != "-" {
+		name = flag.Arg(0)
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(name), ".")
+	}
+	var err error
+	if cfg.Format, err = surrealimport.ParseFormat(*format); err != nil {
+		return fmt.Errorf("%w; use -format", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	db, err := sql.Open("surrealdb", *dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := surrealimport.Import(ctx, db, in, cfg)
+	if res != nil {
+		fmt.Fprintf(os.Stderr, "%d imported, %d failed\n", res.Imported, res.Failed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "resume with -offset %d\n", res.Offset)
+		}
+	}
+	return err
+}
+
+// Splits "a=b,c=d".
+func pairs(s string) map[string]string {
+	out := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok {
+			out[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return out
+}
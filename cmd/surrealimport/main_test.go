@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/IngwiePhoenix/surrealdb-driver/config"
+)
+
+// The documented example must be a DSN the driver accepts.
+func TestExampleDSN(t *testing.T) {
+	example := regexp.MustCompile(`-dsn '([^']+)'`)
+	for _, file := range []string{"main.go", "../../README.md"} {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := example.FindSubmatch(src)
+		if m == nil {
+			t.Errorf("%s: no example", file)
+			continue
+		}
+		if _, err := config.ParseUrl(string(m[1])); err != nil {
+			t.Errorf("%s: %s: %v", file, m[1], err)
+		}
+	}
+}
@@ -1,10 +1,15 @@
 package config
 
 import (
-	"github.com/goccy/go-json"
 	"errors"
 	"fmt"
 	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
 )
 
 type Credentials struct {
@@ -17,6 +22,55 @@ type Credentials struct {
 	Method        AuthMethod
 	URL           *url.URL
 	Extra         map[string]interface{}
+
+	// TLS settings for wss:// connections. CA, Cert and Key may either be a
+	// path to a PEM file or the PEM data itself.
+	TLSCA         string
+	TLSCert       string
+	TLSKey        string
+	TLSServerName string
+	TLSInsecure   bool
+
+	// Proxy to connect through; http:// and socks5:// are supported.
+	Proxy *url.URL
+
+	// Tuning. Zero values mean "no limit" or "library default".
+	ConnectTimeout time.Duration
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	MaxMessageSize int64
+	Compression    bool
+	Protocol       string
+
+	// Keepalive; see the driver's WithHeartbeat option.
+	HeartbeatInterval time.Duration
+	HeartbeatTimeout  time.Duration
+
+	// How strings in results are decoded: strings, schema or heuristic; see
+	// the driver's WithDecoding option. Empty means the default.
+	Decoding string
+}
+
+// Maps every accepted DSN scheme to the WebSocket scheme that is dialed.
+var schemes = map[string]string{
+	"ws":            "ws",
+	"wss":           "wss",
+	"http":          "ws",
+	"https":         "wss",
+	"surrealdb":     "ws",
+	"surrealdb+tls": "wss",
+}
+
+// Every query parameter ParseUrl understands. Anything else is rejected, so
+// that typos surface right away instead of as odd server behaviour.
+var knownParams = map[string]bool{
+	"method": true, "ns": true, "db": true, "ac": true, "token": true, "extra": true,
+	"tls_ca": true, "tls_cert": true, "tls_key": true, "tls_server_name": true, "tls_insecure": true,
+	"proxy":           true,
+	"connect_timeout": true, "read_timeout": true, "write_timeout": true,
+	"max_message_size": true, "compression": true, "protocol": true,
+	"heartbeat": true, "heartbeat_timeout": true,
+	"decoding": true,
 }
 
 func (c *Credentials) GetDBUrl() string {
@@ -28,41 +82,116 @@ func (c *Credentials) GetDBUrl() string {
 	)
 }
 
+// String describes the credentials with all secrets redacted; it is safe to log.
+func (c *Credentials) String() string {
+	out := strings.Builder{}
+	out.WriteString("config.Credentials{")
+	if c.URL != nil {
+		out.WriteString("URL: " + c.GetDBUrl() + ", ")
+	}
+	out.WriteString("Method: " + string(c.Method))
+	field := func(name, value string) {
+		if value != "" {
+			out.WriteString(", " + name + ": " + value)
+		}
+	}
+	field("Username", c.Username)
+	field("Password", redact(c.Password))
+	field("Token", redact(c.Token))
+	field("Namespace", c.Namespace)
+	field("Database", c.Database)
+	field("AccessControl", c.AccessControl)
+	if len(c.Extra) > 0 {
+		keys := make([]string, 0, len(c.Extra))
+		for k := range c.Extra {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		field("Extra", "["+strings.Join(keys, " ")+"]")
+	}
+	if c.Proxy != nil {
+		field("Proxy", c.Proxy.Redacted())
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
+// GoString makes sure %#v does not leak secrets either.
+func (c *Credentials) GoString() string {
+	return c.String()
+}
+
+func redact(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "[REDACTED]"
+}
+
+/*
+ParseUrl parses a DSN of the form
+
+	scheme://[user[:password]@]host[:port][/path]?method=...[&param=value...]
+
+Accepted schemes are ws, wss, http, https, surrealdb and surrealdb+tls; the
+plain ones are dialed as ws://, the TLS ones as wss://. The path defaults to
+/rpc. User, password and parameters are percent-decoded.
+
+Parameters:
+
+	method            root, db, record, token or none (alias: anon)
+	ns, db, ac        namespace, database and access method
+	token             token for method=token
+	extra             JSON object merged into the signin parameters
+	tls_*             see Credentials.TLSConfig
+	proxy             http:// or socks5:// proxy
+	connect_timeout   i.e. 5s; bounds dialing and signing in
+	read_timeout      i.e. 30s; bounds waiting for a response
+	write_timeout     i.e. 5s; bounds sending a request
+	heartbeat         i.e. 30s; ping connections idle for this long
+	heartbeat_timeout i.e. 5s; discard the connection if no pong arrives in time
+	max_message_size  largest accepted response, in bytes
+	compression       true or false
+	protocol          json (cbor is not supported yet)
+	decoding          strings, schema or heuristic; how datetimes and
+	                  durations in results are recognized
+
+A timeout or max_message_size of 0 means none, a heartbeat of 0 no pings;
+negative values are rejected.
+*/
 func ParseUrl(inputUrl string) (*Credentials, error) {
 	u, err := url.Parse(inputUrl)
 	if err != nil {
 		return nil, err
 	}
-	c := &Credentials{}
+	scheme, ok := schemes[strings.ToLower(u.Scheme)]
+	if !ok {
+		return nil, errors.New("unsupported scheme: " + u.Scheme)
+	}
+	u.Scheme = scheme
+	if u.Host == "" {
+		return nil, errors.New("no host specified")
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/rpc"
+	}
+
 	q := u.Query()
+	for key := range q {
+		if !knownParams[key] {
+			return nil, errors.New("unknown parameter: " + key)
+		}
+	}
 	if !q.Has("method") {
 		return nil, errors.New("no authentication method specified")
 	}
 
-	// Sanity check
+	// Assign
+	c := &Credentials{}
 	c.Method = AuthMethod(q.Get("method"))
-	switch c.Method {
-	case AuthMethodRoot:
-		if _, ok := u.User.Password(); !ok && u.User.Username() != "" {
-			return nil, errors.New("root authentication method specified but no username or password provided")
-		}
-	case AuthMethodDB:
-		if _, ok := u.User.Password(); !ok && u.User.Username() != "" && !q.Has("db") && !q.Has("ns") {
-			return nil, errors.New("database authentication method specified but no username, password, namespace and database provided")
-		}
-	case AuthMethodRecord:
-		if _, ok := u.User.Password(); !ok && u.User.Username() != "" && !q.Has("db") && !q.Has("ns") && !q.Has("ac") {
-			return nil, errors.New("record authentication method specified but no username, password, namespace, database and access method provided")
-		}
-	case AuthMethodToken:
-		if !q.Has("token") {
-			return nil, errors.New("token authentication method specified but no access token provided")
-		}
-	default:
-		return nil, errors.New("unknown access method: " + string(c.Method))
+	if c.Method == "anon" {
+		c.Method = AuthMethodAnonymous
 	}
-
-	// Assign
 	c.Username = u.User.Username()
 	c.Password, _ = u.User.Password()
 	c.Database = q.Get("db")
@@ -70,14 +199,124 @@ func ParseUrl(inputUrl string) (*Credentials, error) {
 	c.AccessControl = q.Get("ac")
 	c.Token = q.Get("token")
 
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
 	if q.Has("extra") {
 		extraStr := q.Get("extra")
 		err := json.Unmarshal([]byte(extraStr), &(c.Extra))
+		if err != nil {
+			return nil, errors.New("extra must be a JSON object: " + err.Error())
+		}
+	}
+
+	if err := c.parseTLS(u, q); err != nil {
+		return nil, err
+	}
+	if q.Has("proxy") {
+		proxy, err := ParseProxy(q.Get("proxy"))
 		if err != nil {
 			return nil, err
 		}
+		c.Proxy = proxy
+	}
+	if err := c.parseTuning(q); err != nil {
+		return nil, err
 	}
 
 	c.URL = u
 	return c, nil
 }
+
+// Validate checks that everything the authentication method requires is present.
+func (c *Credentials) Validate() error {
+	var missing []string
+	need := func(name, value string) {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+	switch c.Method {
+	case AuthMethodRoot:
+		need("username", c.Username)
+		need("password", c.Password)
+	case AuthMethodDB:
+		need("username", c.Username)
+		need("password", c.Password)
+		need("ns", c.Namespace)
+		need("db", c.Database)
+	case AuthMethodRecord:
+		// Record access may sign in with arbitrary variables (see extra=), so
+		// username and password are not mandatory.
+		need("ns", c.Namespace)
+		need("db", c.Database)
+		need("ac", c.AccessControl)
+	case AuthMethodToken:
+		need("token", c.Token)
+	case AuthMethodAnonymous:
+	default:
+		return errors.New("unknown authentication method: " + string(c.Method))
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s authentication requires: %s", c.Method, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Reads the timeouts, limits and protocol settings.
+func (c *Credentials) parseTuning(q url.Values) error {
+	durations := []struct {
+		name   string
+		target *time.Duration
+	}{
+		{"connect_timeout", &c.ConnectTimeout},
+		{"read_timeout", &c.ReadTimeout},
+		{"write_timeout", &c.WriteTimeout},
+		{"heartbeat", &c.HeartbeatInterval},
+		{"heartbeat_timeout", &c.HeartbeatTimeout},
+	}
+	for _, d := range durations {
+		if !q.Has(d.name) {
+			continue
+		}
+		v, err := time.ParseDuration(q.Get(d.name))
+		if err != nil || v < 0 {
+			return fmt.Errorf("%s must be a non-negative duration (i.e. 5s, or 0 for none): %s", d.name, q.Get(d.name))
+		}
+		*d.target = v
+	}
+
+	if q.Has("max_message_size") {
+		v, err := strconv.ParseInt(q.Get("max_message_size"), 10, 64)
+		if err != nil || v < 0 {
+			return errors.New("max_message_size must be a non-negative number of bytes (0 for no limit): " + q.Get("max_message_size"))
+		}
+		c.MaxMessageSize = v
+	}
+
+	if q.Has("compression") {
+		v, err := strconv.ParseBool(q.Get("compression"))
+		if err != nil {
+			return errors.New("compression must be a boolean: " + q.Get("compression"))
+		}
+		c.Compression = v
+	}
+
+	c.Decoding = q.Get("decoding")
+	switch c.Decoding {
+	case "", "strings", "schema", "heuristic":
+	default:
+		return errors.New("decoding must be strings, schema or heuristic: " + c.Decoding)
+	}
+
+	c.Protocol = q.Get("protocol")
+	switch c.Protocol {
+	case "", "json":
+	case "cbor":
+		return errors.New("protocol=cbor is not supported yet")
+	default:
+		return errors.New("unknown protocol: " + c.Protocol)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Reads tls_ca, tls_cert, tls_key, tls_server_name and tls_insecure.
+func (c *Credentials) parseTLS(u *url.URL, q url.Values) error {
+	c.TLSCA = q.Get("tls_ca")
+	c.TLSCert = q.Get("tls_cert")
+	c.TLSKey = q.Get("tls_key")
+	c.TLSServerName = q.Get("tls_server_name")
+	if q.Has("tls_insecure") {
+		insecure, err := strconv.ParseBool(q.Get("tls_insecure"))
+		if err != nil {
+			return errors.New("tls_insecure must be a boolean: " + q.Get("tls_insecure"))
+		}
+		c.TLSInsecure = insecure
+	}
+
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return errors.New("tls_cert and tls_key must be specified together")
+	}
+	if c.HasTLS() && u.Scheme == "ws" {
+		return errors.New("tls_* parameters require a wss:// connection")
+	}
+	return nil
+}
+
+// HasTLS reports whether any TLS setting deviates from the defaults.
+func (c *Credentials) HasTLS() bool {
+	return c.TLSCA != "" || c.TLSCert != "" || c.TLSServerName != "" || c.TLSInsecure
+}
+
+// TLSConfig builds a *tls.Config out of the TLS settings, loading the CA and
+// client certificate as needed.
+func (c *Credentials) TLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         c.TLSServerName,
+		InsecureSkipVerify: c.TLSInsecure,
+	}
+	if c.TLSCA != "" {
+		pem, err := readPEM(c.TLSCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("tls_ca does not contain any usable certificate")
+		}
+		cfg.RootCAs = pool
+	}
+	if c.TLSCert != "" {
+		certPEM, err := readPEM(c.TLSCert)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM, err := readPEM(c.TLSKey)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// ParseProxy validates a proxy URL; gorilla/websocket can only speak to
+// HTTP (CONNECT) and SOCKS5 proxies.
+func ParseProxy(in string) (*url.URL, error) {
+	u, err := url.Parse(in)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "socks5":
+	default:
+		return nil, errors.New("unsupported proxy scheme: " + u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, errors.New("proxy has no host: " + in)
+	}
+	return u, nil
+}
+
+// Inline PEM data is taken as-is, everything else is treated as a path.
+func readPEM(in string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(in), "-----BEGIN") {
+		return []byte(in), nil
+	}
+	return os.ReadFile(in)
+}
@@ -5,25 +5,49 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
-	"strconv"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/goccy/go-json"
 	"github.com/tidwall/gjson"
 
 	"github.com/IngwiePhoenix/surrealdb-driver/api"
 	"github.com/IngwiePhoenix/surrealdb-driver/config"
-	"github.com/clok/kemba"
 	"github.com/gorilla/websocket"
 )
 
 // implements driver.Conn
 type SurrealConn struct {
-	WSClient *websocket.Conn
-	Driver   *SurrealDriver
-	Caller   *api.SurrealCaller
-	creds    *config.Credentials
-	k        *kemba.Kemba
-	e        *Debugger
+	WSClient  *websocket.Conn // nil unless the transport is a WebSocket
+	transport Transport
+	Driver    *SurrealDriver
+	Caller    *api.SurrealCaller
+	creds     *config.Credentials
+	connector *SurrealConnector
+	log       *slog.Logger // Carries the connection's ID
+
+	writeMu   sync.Mutex                    // gorilla/websocket allows one writer at a time
+	pendingMu sync.Mutex                    // Guards pending
+	pending   map[api.RequestID]chan []byte // Requests awaiting their response
+	done      chan struct{}                 // Closed once the reader stopped
+	bad       atomic.Bool                   // Set once the connection is unusable
+	badErr    error                         // Why it became unusable
+	closing   atomic.Bool                   // Set by Close(), so that it is not reported as a failure
+	lastSeen  atomic.Int64                  // Unix nanos of the last frame received
+	pingMu    sync.Mutex                    // Serializes pings, so that pongs can be attributed
+	pong      chan struct{}                 // Signalled by the pong handler
+
+	stateMu  sync.Mutex     // Guards draining
+	draining bool           // Set by Shutdown(); no new requests are accepted
+	inflight sync.WaitGroup // Requests sent by callers that await their response
+
+	namespace, database string // As of the last `use`; guarded by stateMu
+
+	liveMu sync.Mutex                                    // Guards lives
+	lives  map[string]chan *api.LiveNotificationResponse // Live queries and their subscribers, if any
 }
 
 var _ driver.Conn = (*SurrealConn)(nil)
@@ -34,238 +58,426 @@ var _ driver.ValueConverter = (*SurrealConn)(nil)
 var _ driver.Pinger = (*SurrealConn)(nil)
 var _ driver.ExecerContext = (*SurrealConn)(nil)
 var _ driver.QueryerContext = (*SurrealConn)(nil)
+var _ driver.Validator = (*SurrealConn)(nil)
+var _ driver.SessionResetter = (*SurrealConn)(nil)
+
+// Prepares the bookkeeping and starts reading from the transport. From here
+// on, only the reader may call ReadMessage.
+func (con *SurrealConn) start() {
+	con.pending = map[api.RequestID]chan []byte{}
+	con.done = make(chan struct{})
+	con.pong = make(chan struct{}, 1)
+	con.lives = map[string]chan *api.LiveNotificationResponse{}
+	con.lastSeen.Store(time.Now().UnixNano())
+	if con.WSClient != nil {
+		con.WSClient.SetPongHandler(func(string) error {
+			con.lastSeen.Store(time.Now().UnixNano())
+			select {
+			case con.pong <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+	}
+	go con.readLoop()
+	if con.WSClient != nil && con.connector != nil && con.connector.HeartbeatInterval > 0 {
+		go con.heartbeat(con.connector.HeartbeatInterval, con.connector.heartbeatTimeout())
+	}
+}
+
+// Reads every incoming message and hands it to whoever waits for it.
+func (con *SurrealConn) readLoop() {
+	defer close(con.done)
+	for {
+		msg, err := con.transport.ReadMessage()
+		if err != nil {
+			if !con.closing.Load() {
+				con.markBad(err)
+			}
+			return
+		}
+		con.lastSeen.Store(time.Now().UnixNano())
+		if m := con.metrics(); m != nil {
+			m.Transferred(0, len(msg))
+		}
+
+		id := gjson.GetBytes(msg, "id")
+		if !id.Exists() {
+			// Live query notifications carry no request ID.
+			if err := con.dispatchLive(msg); err != nil {
+				con.log.Debug("unsolicited message", "error", err)
+			}
+			continue
+		}
+		con.pendingMu.Lock()
+		ch, ok := con.pending[id.String()]
+		delete(con.pending, id.String())
+		con.pendingMu.Unlock()
+		if !ok {
+			// The caller gave up already (i.e. its context was cancelled).
+			con.log.Debug("response without a waiting request", "request_id", id.String())
+			continue
+		}
+		ch <- msg
+	}
+}
+
+// Marks the connection as unusable. The first cause is kept and reported to
+// the OnBadConn hook; the socket is closed so that the reader stops, too.
+func (con *SurrealConn) markBad(cause error) {
+	con.pendingMu.Lock()
+	first := !con.bad.Load()
+	if first {
+		con.badErr = cause
+		con.bad.Store(true)
+	}
+	con.pendingMu.Unlock()
+	if !first {
+		return
+	}
+	con.log.Warn("connection is bad", "error", cause)
+	if m := con.metrics(); m != nil {
+		con.connector.lost.Add(1)
+		m.ConnectionBad()
+	}
+	con.transport.Close()
+	if con.connector != nil && con.connector.Hooks.OnBadConn != nil {
+		con.connector.Hooks.OnBadConn(con, cause)
+	}
+}
+
+// Why the connection went bad, if it did.
+func (con *SurrealConn) cause() error {
+	con.pendingMu.Lock()
+	defer con.pendingMu.Unlock()
+	return con.badErr
+}
 
 // Execute directly on the underlying WebSockets connection by utilizing the
 // raw API objects.
-func (con *SurrealConn) execObj(req *api.Request) (*api.Response, error) {
-	k := con.k.Extend("execObj")
-	k.Log("received", req)
+func (con *SurrealConn) execObj(ctx context.Context, req *api.Request) (*api.Response, error) {
+	con.stateMu.Lock()
+	if con.draining {
+		con.stateMu.Unlock()
+		return nil, driver.ErrBadConn
+	}
+	con.inflight.Add(1)
+	con.stateMu.Unlock()
+	defer con.inflight.Done()
+	return con.send(ctx, req)
+}
 
-	// For debugging only
-	if err := con.WSClient.WriteJSON(req); con.e.Debug(err) {
-		return nil, err
+// Sends a request through the middleware and waits for its response. Unlike
+// execObj, this works while shutting down, too.
+func (con *SurrealConn) send(ctx context.Context, req *api.Request) (res *api.Response, err error) {
+	if m := con.metrics(); m != nil {
+		m.RequestStarted(req.Method)
+		start := time.Now()
+		defer func() {
+			m.RequestDone(req.Method, time.Since(start), api.Classify(err))
+			if res != nil && req.Method == api.APIMethodQuery {
+				observeStatements(m, res)
+			}
+		}()
 	}
+	if con.log.Enabled(ctx, slog.LevelDebug) {
+		start := time.Now()
+		defer func() {
+			con.logRPC(ctx, req, time.Since(start), err)
+		}()
+	}
+	if con.connector == nil || len(con.connector.Middleware) == 0 {
+		return con.roundTrip(ctx, req)
+	}
+	return runMiddleware(ctx, con, con.connector.Middleware, req, con.roundTrip)
+}
 
-	mtyp, msg, err := con.WSClient.ReadMessage()
-	if con.e.Debug(err) {
-		return nil, err
-	} else if mtyp != websocket.BinaryMessage && mtyp != websocket.TextMessage {
-		k.Log("got wrong WS message", mtyp)
+// Logs a finished RPC. Parameters are left out; they may hold anything.
+func (con *SurrealConn) logRPC(ctx context.Context, req *api.Request, d time.Duration, err error) {
+	attrs := []slog.Attr{
+		slog.String("request_id", req.ID),
+		slog.String("method", string(req.Method)),
+		slog.String("ns", con.Namespace()),
+		slog.String("db", con.Database()),
+		slog.Duration("duration", d),
+	}
+	if err != nil {
+		attrs = append(attrs,
+			slog.String("error", err.Error()),
+			slog.String("error_class", string(api.Classify(err))),
+		)
+	}
+	con.log.LogAttrs(ctx, slog.LevelDebug, "rpc", attrs...)
+}
 
-		switch mtyp {
-		case websocket.CloseMessage:
-			return nil, errors.New("received unexpected CloseMessage")
+// Writes a request and waits for its response.
+func (con *SurrealConn) roundTrip(ctx context.Context, req *api.Request) (*api.Response, error) {
+	if con.bad.Load() {
+		return nil, driver.ErrBadConn
+	}
 
-		case websocket.PingMessage, websocket.PongMessage:
-			return nil, errors.New("received unexpected Ping/Pong message")
+	ch := make(chan []byte, 1)
+	con.pendingMu.Lock()
+	con.pending[req.ID] = ch
+	con.pendingMu.Unlock()
+	forget := func() {
+		con.pendingMu.Lock()
+		delete(con.pending, req.ID)
+		con.pendingMu.Unlock()
+	}
 
-		default:
-			return nil, fmt.Errorf("received unrecognized, unexpected message type %d", mtyp)
-		}
-	} else {
-		// And this is where all my troubble begins, and ends.
-		res, err := validateResponse(req.Method, msg)
+	data, err := json.Marshal(req)
+	if err != nil {
+		forget()
+		return nil, err
+	}
+	con.writeMu.Lock()
+	err = con.transport.WriteMessage(data)
+	con.writeMu.Unlock()
+	if err != nil {
+		forget()
+		con.markBad(err)
+		return nil, err
+	}
+	if m := con.metrics(); m != nil {
+		m.Transferred(len(data), 0)
+	}
 
-		if err != nil {
-			k.Log("error in validate", err)
-			return nil, err
-		} else {
-			k.Log("received", string(msg), res)
-
-			// Only Queries produce legible errors. The rest just kinda... does not. o.o
-			// If it did throw an error, it'd be above.
-			queryErrors := []error{}
-			if req.Method == api.APIMethodQuery {
-				errMsgs := res.Result.Get(`#(status!="OK")#.result`)
-				if errMsgs.IsArray() {
-					errMsgs.ForEach(func(_, value gjson.Result) bool {
-						queryErrors = append(queryErrors, errors.New(value.String()))
-						return true
-					})
-				}
-			}
+	var timeout <-chan time.Time
+	if con.connector != nil && con.connector.ReadTimeout > 0 {
+		timer := time.NewTimer(con.connector.ReadTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
 
-			k.Log("done", res, queryErrors)
-			return res, errors.Join(queryErrors...)
+	var msg []byte
+	select {
+	case msg = <-ch:
+	case <-ctx.Done():
+		forget()
+		return nil, ctx.Err()
+	case <-timeout:
+		forget()
+		// There is no telling if the response will ever arrive; better not
+		// to hand this connection out again.
+		err := fmt.Errorf("no response to %s within %s", req.Method, con.connector.ReadTimeout)
+		con.markBad(err)
+		return nil, err
+	case <-con.done:
+		forget()
+		if cause := con.cause(); cause != nil {
+			return nil, cause
 		}
+		return nil, driver.ErrBadConn
+	}
+
+	// And this is where all my troubble begins, and ends.
+	res, err := validateResponse(req.Method, msg)
+	if err != nil {
+		return nil, err
 	}
-	//panic("reached execObj(...) unexpectedly")
+	con.trackLive(req, res)
+	if req.Method == api.APIMethodUse {
+		con.trackUse(req)
+	}
+
+	// Only Queries produce legible errors. The rest just kinda... does not. o.o
+	// If it did throw an error, it'd be above.
+	queryErrors := []error{}
+	if req.Method == api.APIMethodQuery {
+		res.Result.ForEach(func(idx, stmt gjson.Result) bool {
+			if stmt.Get("status").String() != "OK" {
+				queryErrors = append(queryErrors, &api.QueryError{
+					Statement: int(idx.Int()),
+					Message:   stmt.Get("result").String(),
+					Time:      stmt.Get("time").String(),
+				})
+			}
+			return true
+		})
+	}
+
+	return res, errors.Join(queryErrors...)
 }
 
 // Execute directly on the underlying WebSockets connection
-func (con *SurrealConn) execRaw(sql string, args map[string]interface{}) (*api.Response, error) {
-	k := con.k.Extend("execRaw")
-	k.Log("start", sql, args)
-	return con.execObj(con.Caller.CallQuery(sql, args))
+func (con *SurrealConn) execRaw(ctx context.Context, sql string, args map[string]interface{}) (*api.Response, error) {
+	return con.execObj(ctx, con.Caller.CallQuery(sql, args))
 }
 
-func (con *SurrealConn) execWithArgs(sql string, args map[string]interface{}) (driver.Result, error) {
-	k := con.k.Extend("execWithArgs")
-	k.Log("start", sql, args)
-	res, err := con.execObj(con.Caller.CallQuery(sql, args))
-	if con.e.Debug(err) {
+func (con *SurrealConn) execWithArgs(ctx context.Context, sql string, args map[string]interface{}) (driver.Result, error) {
+	sql, args = bindParams(sql, args)
+	res, err := con.execObj(ctx, con.Caller.CallQuery(sql, args))
+	con.noteSchemaChange(sql)
+	if err != nil {
 		return nil, err
 	}
-	newk := localKemba.Extend("result")
 	return &SurrealResult{
 		RawResult: res,
-		k:         newk,
-		e:         makeErrorLogger(newk),
 	}, err
 }
 
-func (con *SurrealConn) queryWithArgs(sql string, args map[string]interface{}) (driver.Rows, error) {
-	k := con.k.Extend("")
-	k.Log("start", sql, args)
-	res, err := con.execObj(con.Caller.CallQuery(sql, args))
-	if con.e.Debug(err) {
+func (con *SurrealConn) queryWithArgs(ctx context.Context, sql string, args map[string]interface{}) (driver.Rows, error) {
+	sql, args = bindParams(sql, args)
+	res, err := con.execObj(ctx, con.Caller.CallQuery(sql, args))
+	con.noteSchemaChange(sql)
+	if err != nil {
 		return nil, err
 	}
-	newk := localKemba.Extend("rows")
 	return &SurrealRows{
 		conn:      con,
 		RawResult: res,
 		resultIdx: 0,
-		k:         newk,
-		e:         makeErrorLogger(newk),
+		decoding:  con.decoding(),
+		schemas:   con.loadSchemas(ctx, res.Result.Array()),
 	}, err
 }
 
-func (con *SurrealConn) performLogin() error {
-	k := con.k.Extend("performLogin")
-	msg, err := con.Caller.CallSignin(con.creds)
-	if con.e.Debug(err) {
-		return err
+func (con *SurrealConn) performLogin(ctx context.Context) error {
+	var msg *api.Request
+	switch con.creds.Method {
+	case config.AuthMethodAnonymous:
+		// Nothing to sign into; go straight to `use`.
+	case config.AuthMethodToken:
+		msg = con.Caller.CallAuthenticate(con.creds.Token)
+	default:
+		var err error
+		msg, err = con.Caller.CallSignin(con.creds)
+		if err != nil {
+			return err
+		}
 	}
-	rawMsg, _ := json.Marshal(msg)
-	k.Log("message", string(rawMsg))
-	res, err := con.execObj(msg)
-	if con.e.Debug(err) {
-		return err
+	if msg != nil {
+		if _, err := con.execObj(ctx, msg); err != nil {
+			return err
+		}
+		con.log.DebugContext(ctx, "signed in", "method", con.creds.Method)
 	}
 	// Attempt to run a `use [ns, db]`. Strings are empty (thus "null") by default.
-	if con.creds.Method == config.AuthMethodDB || con.creds.Method == config.AuthMethodRoot {
-		msg = con.Caller.CallUse(con.creds.Namespace, con.creds.Database)
-		res, err = con.execObj(msg)
-		if con.e.Debug(err) {
+	// Record users are bound to their namespace and database already.
+	if con.creds.Method != config.AuthMethodRecord &&
+		(con.creds.Namespace != "" || con.creds.Database != "") {
+		if _, err := con.execObj(ctx, con.Caller.CallUse(con.creds.Namespace, con.creds.Database)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Defines the connector's session variables on this connection.
+func (con *SurrealConn) applySessionVars(ctx context.Context) error {
+	if con.connector == nil || len(con.connector.SessionVars) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(con.connector.SessionVars))
+	for key := range con.connector.SessionVars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := con.execObj(ctx, con.Caller.CallLet(key, con.connector.SessionVars[key])); err != nil {
 			return err
 		}
 	}
-	k.Log("success", res)
 	return nil
 }
 
 func (con *SurrealConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
-	k := con.k.Extend("PrepareContext")
-	k.Log("start", ctx, query)
 	// TODO: Take advantage of a given context.
-	newk := localKemba.Extend("stmt")
 	return &SurrealStmt{
 		conn:  con,
 		query: query,
-		k:     newk,
-		e:     makeErrorLogger(newk),
 	}, nil
 }
 func (con *SurrealConn) Prepare(query string) (driver.Stmt, error) {
-	k := con.k.Extend("Prepare")
-	k.Log("start", query)
 	return con.PrepareContext(context.Background(), query)
 }
 
-func (con *SurrealConn) Close() error {
-	k := con.k.Extend("Close")
-	k.Log("bye")
-	return con.WSClient.Close()
-}
-
 func (con *SurrealConn) Begin() (driver.Tx, error) {
-	k := con.k.Extend("Begin")
-	k.Log("start")
 	return con.BeginTx(context.Background(), driver.TxOptions{})
 }
 
+// implements driver.Validator
 func (con *SurrealConn) IsValid() bool {
-	k := con.k.Extend("IsValid")
-	if err := con.WSClient.WriteMessage(websocket.PingMessage, nil); err != nil {
-		k.Log("not valid", err)
+	if con.bad.Load() {
 		return false
 	}
-	k.Log("is still valid")
-	return true
+	con.stateMu.Lock()
+	draining := con.draining
+	con.stateMu.Unlock()
+	return !draining
+}
+
+// Namespace returns the namespace the session uses.
+func (con *SurrealConn) Namespace() string {
+	con.stateMu.Lock()
+	defer con.stateMu.Unlock()
+	return con.namespace
+}
+
+// Database returns the database the session uses.
+func (con *SurrealConn) Database() string {
+	con.stateMu.Lock()
+	defer con.stateMu.Unlock()
+	return con.database
+}
+
+// Remembers what a successful `use` switched to.
+func (con *SurrealConn) trackUse(req *api.Request) {
+	params, ok := req.Params.([]interface{})
+	if !ok || len(params) != 2 {
+		return
+	}
+	con.stateMu.Lock()
+	defer con.stateMu.Unlock()
+	if ns, ok := params[0].(string); ok {
+		con.namespace = ns
+	}
+	if db, ok := params[1].(string); ok {
+		con.database = db
+	}
+}
+
+// implements driver.SessionResetter
+func (con *SurrealConn) ResetSession(ctx context.Context) error {
+	if !con.IsValid() {
+		return driver.ErrBadConn
+	}
+	return nil
 }
 
 func (con *SurrealConn) ExecContext(ctx context.Context, sql string, args []driver.NamedValue) (driver.Result, error) {
-	k := con.k.Extend("ExecContext")
-	k.Log("start", ctx, sql, args)
-	// TODO: Use the provided context
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
-		k.Log("converting args")
-		mappedValues := map[string]interface{}{}
-		for _, v := range args {
-			var key string
-			if len(v.Name) == 0 || v.Name == "" {
-				key = "_" + strconv.Itoa(v.Ordinal)
-			} else {
-				key = "_" + v.Name
-			}
-			k.Log("picking:", key, " O: ", v.Ordinal, " N: ", v.Name, " V: ", v.Value)
-			mappedValues[key] = v.Value
-		}
-		return con.execWithArgs(sql, mappedValues)
+		return con.execWithArgs(ctx, sql, namedArgs(args))
 	}
 }
 
 // QueryContext implements driver.QueryerContext.
 func (con *SurrealConn) QueryContext(ctx context.Context, sql string, args []driver.NamedValue) (driver.Rows, error) {
-	k := con.k.Extend("QueryContext")
-	k.Log("start", ctx, sql, args)
-	// TODO: Use the provided context
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
-		k.Log("converting args")
-		mappedValues := map[string]interface{}{}
-		for _, v := range args {
-			var key string
-			if len(v.Name) == 0 || v.Name == "" {
-				key = "_" + strconv.Itoa(v.Ordinal)
-			} else {
-				key = "_" + v.Name
-			}
-			k.Log("picking:", key, " O: ", v.Ordinal, " N: ", v.Name, " V: ", v.Value)
-			mappedValues[key] = v.Value
-		}
-		return con.queryWithArgs(sql, mappedValues)
+		return con.queryWithArgs(ctx, sql, namedArgs(args))
 	}
 }
 
 func (con *SurrealConn) Exec(sql string, values []driver.Value) (driver.Result, error) {
-	k := con.k.Extend("Exec")
-	k.Log("start")
-	mappedValues := map[string]interface{}{}
-	for key, v := range values {
-		mappedValues["_"+string(rune(key))] = v
-	}
-	return con.execWithArgs(sql, mappedValues)
+	return con.execWithArgs(context.Background(), sql, valueArgs(values))
 }
 
 // implements driver.ConnBeginTx
 func (con *SurrealConn) BeginTx(ctx context.Context, _ driver.TxOptions) (driver.Tx, error) {
-	k := con.k.Extend("BeginTx")
-	k.Log("start", ctx)
-	// TODO: Figure out how to take the provided ctx into account.
 	// TODO: Can we use the TxOptions?
 	if !con.IsValid() {
 		return nil, driver.ErrBadConn
 	}
 
 	// TODO: Use the response to determine if everything is still fine.
-	_, err := con.Exec("BEGIN TRANSACTION;", nil)
+	_, err := con.execWithArgs(ctx, "BEGIN TRANSACTION;", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -276,28 +488,44 @@ func (con *SurrealConn) BeginTx(ctx context.Context, _ driver.TxOptions) (driver
 }
 
 func (con *SurrealConn) CheckNamedValue(nv *driver.NamedValue) (err error) {
-	k := con.k.Extend("CheckNamedValue")
-	k.Log("start", nv.Name, nv.Ordinal, nv.Value)
 	nv.Value, err = checkNamedValue(nv.Value)
 	return
 }
 
 func (con *SurrealConn) ConvertValue(v any) (driver.Value, error) {
-	k := con.k.Extend("ConvertValue")
-	k.Log("start", v)
 	return checkNamedValue(v)
 }
 
+// Sends a WebSocket ping and waits for the pong, bounded by ctx. Without a
+// deadline on ctx, the heartbeat timeout is used.
 func (con *SurrealConn) Ping(ctx context.Context) error {
-	k := con.k.Extend("Ping")
-	k.Log("pingpongdong")
-	// TODO: Figure out how to use contexts
 	if !con.IsValid() {
-		return errors.New("invalid connection")
+		return driver.ErrBadConn
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		timeout := defaultHeartbeatTimeout
+		if con.connector != nil {
+			timeout = con.connector.heartbeatTimeout()
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
-	msg, err := websocket.NewPreparedMessage(websocket.PingMessage, nil)
-	if con.e.Debug(err) {
+	if err := con.ping(ctx); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			con.markBad(err)
+		}
 		return err
 	}
-	return con.WSClient.WritePreparedMessage(msg)
+	return nil
+}
+
+// Insert creates records in a table through the `insert` RPC and returns
+// them as created. data is a single record or a slice of them.
+func (con *SurrealConn) Insert(ctx context.Context, table string, data any) (gjson.Result, error) {
+	res, err := con.execObj(ctx, con.Caller.CallInsert(table, data))
+	if err != nil {
+		return gjson.Result{}, err
+	}
+	return res.Result, nil
 }
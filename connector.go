@@ -4,57 +4,163 @@ import (
 	"context"
 	"database/sql/driver"
 	"errors"
+	"log/slog"
 	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/IngwiePhoenix/surrealdb-driver/api"
 	"github.com/IngwiePhoenix/surrealdb-driver/config"
-	"github.com/clok/kemba"
+	"github.com/IngwiePhoenix/surrealdb-driver/internal/logging"
 	"github.com/gorilla/websocket"
 )
 
 // implements driver.Connector
 type SurrealConnector struct {
-	Creds  *config.Credentials
-	Dialer *websocket.Dialer
-	driver *SurrealDriver
-	k      *kemba.Kemba
-	e      *Debugger
+	Creds             *config.Credentials
+	Dialer            *websocket.Dialer
+	Headers           http.Header    // Extra headers for the WebSocket handshake
+	DialTimeout       time.Duration  // Upper bound for dialing; 0 means none
+	ReadTimeout       time.Duration  // Upper bound for awaiting a response
+	WriteTimeout      time.Duration  // Upper bound for sending a request
+	MaxMessageSize    int64          // Largest accepted response in bytes
+	HeartbeatInterval time.Duration  // Ping idle connections this often; 0 disables
+	HeartbeatTimeout  time.Duration  // How long to wait for a pong
+	CloseTimeout      time.Duration  // Upper bound for a graceful Close()
+	Protocol          string         // WebSocket sub-protocol
+	SessionVars       map[string]any // Variables set via `let` on every connection
+	Hooks             Hooks
+	Middleware        []Middleware // Wrapped around every RPC, outermost first
+	Metrics           Metrics      // Optional
+	Decoding          Decoding     // How strings in results are decoded
+	dialTransport     TransportFunc
+	transportWrappers []func(Transport) Transport
+	driver            *SurrealDriver
+	logger            *slog.Logger // nil means the package-level logger
+	lost              atomic.Int64 // Connections gone bad and not replaced yet
+	schemas           schemaCache  // Field types for DecodeSchema
 }
 
 var _ driver.Connector = (*SurrealConnector)(nil)
 
 func (c *SurrealConnector) Connect(ctx context.Context) (driver.Conn, error) {
-	k := c.k.Extend("Connect")
+	if c.Hooks.BeforeConnect != nil {
+		ctx = c.Hooks.BeforeConnect(ctx)
+	}
+	con, err := c.connect(ctx)
+	if c.Metrics != nil {
+		reconnect := false
+		if err == nil {
+			// Any new connection after one went bad replaces it.
+			for lost := c.lost.Load(); lost > 0 && !reconnect; lost = c.lost.Load() {
+				reconnect = c.lost.CompareAndSwap(lost, lost-1)
+			}
+		}
+		c.Metrics.Connected(api.Classify(err), reconnect)
+	}
+	if err != nil {
+		if c.Hooks.OnConnectError != nil {
+			c.Hooks.OnConnectError(ctx, err)
+		}
+		return nil, err
+	}
+	if c.Hooks.OnConnect != nil {
+		c.Hooks.OnConnect(ctx, con)
+	}
+	return con, nil
+}
+
+func (c *SurrealConnector) connect(ctx context.Context) (*SurrealConn, error) {
+	if c.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.DialTimeout)
+		defer cancel()
+	}
+
+	var wsConn *websocket.Conn
+	var transport Transport
+	var err error
+	if c.dialTransport != nil {
+		transport, err = c.dialTransport(ctx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		wsConn, err = c.dialWebSocket(ctx)
+		if err != nil {
+			return nil, err
+		}
+		transport = &wsTransport{conn: wsConn, writeTimeout: c.WriteTimeout}
+	}
+	for _, wrap := range c.transportWrappers {
+		transport = wrap(transport)
+	}
+
+	caller := api.MakeCaller()
+	con := &SurrealConn{
+		WSClient:  wsConn,
+		transport: transport,
+		Driver:    c.driver,
+		Caller:    caller,
+		creds:     c.Creds,
+		connector: c,
+		log:       c.log().With("conn_id", caller.ConnID),
+		namespace: c.Creds.Namespace,
+		database:  c.Creds.Database,
+	}
+
+	con.start()
 
-	k.Log("start", c.Creds.GetDBUrl())
+	// Signing in counts towards the dial timeout, too.
+	if err = con.performLogin(ctx); err != nil {
+		con.log.DebugContext(ctx, "signing in failed", "error", err)
+		con.Close()
+		return nil, err
+	}
+	if err = con.applySessionVars(ctx); err != nil {
+		con.log.DebugContext(ctx, "setting session variables failed", "error", err)
+		con.Close()
+		return nil, err
+	}
+	con.log.InfoContext(ctx, "connected", "url", c.Creds.GetDBUrl(), "ns", con.namespace, "db", con.database)
+	return con, nil
+}
 
+func (c *SurrealConnector) dialWebSocket(ctx context.Context) (*websocket.Conn, error) {
 	headers := http.Header{}
 	headers.Add("Content-Type", "application/json")
 	headers.Add("Accept", "application/json")
-	headers.Add("Sec-WebSocket-Protocol", "json") // why x.x
+	for k, vs := range c.Headers {
+		for _, v := range vs {
+			headers.Add(k, v)
+		}
+	}
 
-	conn, resp, err := c.Dialer.DialContext(ctx, c.Creds.GetDBUrl(), headers)
-	if c.e.Debug(err) {
+	// Copy, so that the sub-protocol never leaks into a user-supplied dialer.
+	dialer := *c.Dialer
+	dialer.Subprotocols = []string{c.Protocol}
+
+	conn, resp, err := dialer.DialContext(ctx, c.Creds.GetDBUrl(), headers)
+	if err != nil {
+		c.log().DebugContext(ctx, "dialing failed", "url", c.Creds.GetDBUrl(), "error", err)
 		return nil, err
 	}
-	k.Log("http response", resp)
 	if resp.StatusCode != 200 && resp.StatusCode != 101 {
+		conn.Close()
 		return nil, errors.New("SurrealDB's initial response was not 200/101: " + resp.Status)
 	}
-	connk := localKemba.Extend("connection")
-	con := &SurrealConn{
-		WSClient: conn,
-		Driver:   c.driver,
-		Caller:   api.MakeCaller(),
-		creds:    c.Creds,
-		k:        connk,
-		e:        makeErrorLogger(connk),
+	if c.MaxMessageSize > 0 {
+		conn.SetReadLimit(c.MaxMessageSize)
 	}
+	return conn, nil
+}
 
-	if err = con.performLogin(); c.e.Debug(err) {
-		return nil, err
+// The logger set via WithLogger, or else the package-level one.
+func (c *SurrealConnector) log() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
 	}
-	return con, nil
+	return logging.Logger()
 }
 
 func (s *SurrealConnector) Driver() driver.Driver {
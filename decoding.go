@@ -0,0 +1,162 @@
+package surrealdbdriver
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
+	"github.com/tidwall/gjson"
+)
+
+// Decoding decides how strings in results are turned into Go values. JSON
+// has no datetimes or durations, so SurrealDB sends those as strings; which
+// strings were meant as such is not visible in the result itself.
+type Decoding int
+
+const (
+	// DecodeStrings leaves every string a string. This is the default.
+	DecodeStrings Decoding = iota
+	// DecodeSchema looks up the type of each field in its table's schema
+	// (`INFO FOR TABLE`): datetime fields become time.Time, duration fields
+	// time.Duration, unless they are too long for one. Everything else,
+	// including fields of schemaless tables, stays a string. The table is
+	// taken from the record's id.
+	DecodeSchema
+	// DecodeHeuristic turns every string that parses as an RFC 3339 time
+	// into a time.Time, and every one that parses as a SurrealQL duration
+	// into a time.Duration, whatever the field. A name like "1h" changes
+	// type.
+	DecodeHeuristic
+)
+
+// ParseDecoding looks up a decoding by name: strings, schema or heuristic.
+func ParseDecoding(name string) (Decoding, error) {
+	switch strings.ToLower(name) {
+	case "strings":
+		return DecodeStrings, nil
+	case "schema":
+		return DecodeSchema, nil
+	case "heuristic":
+		return DecodeHeuristic, nil
+	}
+	return 0, fmt.Errorf("unknown decoding %q", name)
+}
+
+// WithDecoding sets how strings in results are decoded.
+//
+// With DecodeSchema, schemas are fetched once per table and cached by the
+// connector. Queries defining or removing tables or fields through the
+// driver drop the cache; for changes made elsewhere, call ForgetSchemas.
+func WithDecoding(decoding Decoding) Option {
+	return func(c *SurrealConnector) error {
+		switch decoding {
+		case DecodeStrings, DecodeSchema, DecodeHeuristic:
+			c.Decoding = decoding
+			return nil
+		}
+		return fmt.Errorf("unknown decoding %d", decoding)
+	}
+}
+
+// ForgetSchemas drops the cached table schemas, see DecodeSchema.
+func (c *SurrealConnector) ForgetSchemas() {
+	c.schemas.forget()
+}
+
+// Field types by field name, i.e. "datetime".
+type tableSchema map[string]string
+
+type schemaCache struct {
+	mu     sync.Mutex
+	tables map[string]tableSchema // By namespace, database and table
+}
+
+func (sc *schemaCache) get(key string) (tableSchema, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	schema, ok := sc.tables[key]
+	return schema, ok
+}
+
+func (sc *schemaCache) put(key string, schema tableSchema) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.tables == nil {
+		sc.tables = map[string]tableSchema{}
+	}
+	sc.tables[key] = schema
+}
+
+func (sc *schemaCache) forget() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.tables = nil
+}
+
+func (con *SurrealConn) decoding() Decoding {
+	if con.connector == nil {
+		return DecodeStrings
+	}
+	return con.connector.Decoding
+}
+
+var schemaChange = regexp.MustCompile(`(?i)\b(DEFINE|REMOVE|ALTER)\s+(TABLE|FIELD)\b`)
+
+// Drops the cached schemas if sql might change them.
+func (con *SurrealConn) noteSchemaChange(sql string) {
+	if con.decoding() == DecodeSchema && schemaChange.MatchString(sql) {
+		con.connector.ForgetSchemas()
+	}
+}
+
+// The schemas of the tables the records in a query's result come from, by
+// table name. nil unless DecodeSchema is used.
+func (con *SurrealConn) loadSchemas(ctx context.Context, statements []gjson.Result) map[string]tableSchema {
+	if con.decoding() != DecodeSchema {
+		return nil
+	}
+	out := map[string]tableSchema{}
+	for _, stmt := range statements {
+		records := stmt.Get("result")
+		if !records.IsArray() {
+			records = gjson.Parse("[" + records.Raw + "]")
+		}
+		records.ForEach(func(_, record gjson.Result) bool {
+			table, _ := st.TableOf(record.Get("id").String())
+			if _, ok := out[table]; table != "" && !ok {
+				out[table] = con.loadSchema(ctx, table)
+			}
+			return true
+		})
+	}
+	return out
+}
+
+// Matches the type of a field definition, i.e. `TYPE option<datetime>`.
+var fieldType = regexp.MustCompile(`(?i)\bTYPE\s+(?:option\s*<\s*)?([a-z]+)`)
+
+func (con *SurrealConn) loadSchema(ctx context.Context, table string) tableSchema {
+	key := con.Namespace() + "\x00" + con.Database() + "\x00" + table
+	if schema, ok := con.connector.schemas.get(key); ok {
+		return schema
+	}
+	schema := tableSchema{}
+	res, err := con.execObj(ctx, con.Caller.CallQuery("INFO FOR TABLE "+st.QuoteTable(table), nil))
+	if err != nil {
+		// Not allowed to look, most likely; the table is decoded as if it
+		// had no schema, and not asked about again.
+		con.log.WarnContext(ctx, "reading the table schema failed", "table", table, "error", err)
+	} else {
+		res.Result.Get("0.result.fields").ForEach(func(name, definition gjson.Result) bool {
+			if m := fieldType.FindStringSubmatch(definition.String()); m != nil {
+				schema[name.String()] = strings.ToLower(m[1])
+			}
+			return true
+		})
+	}
+	con.connector.schemas.put(key, schema)
+	return schema
+}
@@ -1,20 +1,16 @@
 package surrealdbdriver
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
-	"errors"
+	"log/slog"
 
-	"github.com/IngwiePhoenix/surrealdb-driver/config"
-	"github.com/clok/kemba"
-	"github.com/gorilla/websocket"
+	"github.com/IngwiePhoenix/surrealdb-driver/internal/logging"
 )
 
 // implements driver.Driver
-type SurrealDriver struct {
-	k *kemba.Kemba
-	e *Debugger
-}
+type SurrealDriver struct{}
 
 var _ driver.Driver = (*SurrealDriver)(nil)
 var _ driver.DriverContext = (*SurrealDriver)(nil)
@@ -22,52 +18,35 @@ var _ driver.DriverContext = (*SurrealDriver)(nil)
 //var _ sql.DB = (*SurrealDriver)(nil)
 
 func (d *SurrealDriver) Open(address string) (driver.Conn, error) {
-	k := d.k.Extend("Open")
-	k.Println("address", address)
-	config, err := config.ParseUrl(address)
-	if err != nil {
-		return nil, err
-	}
-	k.Println("config", config)
-	dialer := websocket.Dialer{}
-	conn, resp, err := dialer.Dial(config.GetDBUrl(), nil)
+	connector, err := d.OpenConnector(address)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != 200 {
-		return nil, errors.New("SurrealDB's initial response was not 200: " + resp.Status)
-	}
-	return &SurrealConn{
-		WSClient: conn,
-		Driver:   d,
-	}, nil
+	return connector.Connect(context.Background())
 }
 
 // implements driver.DriverContext
 func (d *SurrealDriver) OpenConnector(address string) (driver.Connector, error) {
-	k := d.k.Extend("OpenConnector")
-	k.Println("address", address)
-	config, err := config.ParseUrl(address)
+	connector, err := NewConnector(WithDSN(address))
 	if err != nil {
 		return nil, err
 	}
-	k.Println("config", config)
-	newk := localKemba.Extend("connector")
-	return &SurrealConnector{
-		Creds:  config,
-		Dialer: &websocket.Dialer{},
-		driver: d,
-		k:      newk,
-		e:      makeErrorLogger(newk),
-	}, nil
+	connector.driver = d
+	return connector, nil
 }
 
 var SurrealDBDriver *SurrealDriver
-var localKemba = kemba.New("surrealdb:driver")
 
 func init() {
-	k := localKemba
-	SurrealDBDriver = &SurrealDriver{k: k, e: makeErrorLogger(k)}
+	SurrealDBDriver = &SurrealDriver{}
 	sql.Register("surrealdb", SurrealDBDriver)
-	k.Log("Driver is now added.")
+}
+
+// SetLogger sets the logger used by the driver, pkg/rel and pkg/gorm, unless
+// a connector got its own via WithLogger. By default, nothing is logged.
+//
+// Attributes whose key looks like a secret (password, token, secret, ...)
+// are logged as [REDACTED]. Queries' parameters are never logged.
+func SetLogger(logger *slog.Logger) {
+	logging.SetLogger(logger)
 }
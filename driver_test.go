@@ -1,16 +1,45 @@
 package surrealdbdriver_test
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"log/slog"
+	"math"
+	"strings"
 	"testing"
+	"time"
+
+	surrealdbdriver "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/IngwiePhoenix/surrealdb-driver/api"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealtest"
+	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
 )
 
 func TestDriverCreation(t *testing.T) {
-	db, err := sql.Open("surrealdb", "ws://root:root@localhost:8000/rpc?method=root&db=dsbt&ns=dsbt")
+	srv := surrealtest.NewServer(t)
+	srv.OnQuery("info for root;").Return(map[string]any{
+		"accesses":   map[string]any{},
+		"namespaces": map[string]any{"dsbt": "DEFINE NAMESPACE dsbt"},
+		"nodes":      map[string]any{},
+		"system":     map[string]any{"available_parallelism": 8},
+		"users":      map[string]any{"root": "DEFINE USER root ON ROOT PASSHASH '...' ROLES OWNER"},
+	})
+	srv.OnQuery(`RETURN "foo";`).Return("foo")
+	srv.OnQueryMatch(`^RETURN \{`).Return(map[string]any{"life": 42, "testWords": []string{"foo", "bar", "baz"}})
+	srv.OnQuery(`THROW "aqua"`).ReturnError("An error occurred: aqua")
+	srv.OnQuery("INFO FOR DB;").Return(map[string]any{
+		"accesses": map[string]any{}, "analyzers": map[string]any{}, "apis": map[string]any{},
+		"configs": map[string]any{}, "functions": map[string]any{}, "models": map[string]any{},
+		"params": map[string]any{}, "tables": map[string]any{}, "users": map[string]any{},
+	})
+
+	db, err := sql.Open("surrealdb", srv.DSN())
 	if err != nil {
 		t.Error(err)
 	}
+	defer db.Close()
 
 	t.Run("RunInfoAsExec", func(t *testing.T) {
 		t.Log("grab info for root")
@@ -121,3 +150,488 @@ func TestDriverCreation(t *testing.T) {
 		}
 	})
 }
+
+func TestLiveQueries(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	srv.OnQuery("LIVE SELECT * FROM person").ReturnLive()
+
+	db, err := sql.Open("surrealdb", srv.DSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Notifications", func(t *testing.T) {
+		err := conn.Raw(func(dc any) error {
+			sc := dc.(*surrealdbdriver.SurrealConn)
+			id, ch, err := sc.Live(ctx, "person", false)
+			if err != nil {
+				return err
+			}
+			if err := srv.Notify(id, "CREATE", map[string]any{"id": "person:tobie"}); err != nil {
+				return err
+			}
+			select {
+			case n := <-ch:
+				if n.Action != "CREATE" || n.Result.Get("id").String() != "person:tobie" {
+					t.Errorf("unexpected notification: %+v", n)
+				}
+			case <-time.After(time.Second):
+				t.Error("no notification arrived")
+			}
+			return sc.Kill(ctx, id)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("KilledOnClose", func(t *testing.T) {
+		if _, err := conn.ExecContext(ctx, "LIVE SELECT * FROM person"); err != nil {
+			t.Fatal(err)
+		}
+		if n := len(srv.LiveQueries()); n != 1 {
+			t.Fatalf("%d live queries, want 1", n)
+		}
+		conn.Raw(func(dc any) error {
+			return dc.(*surrealdbdriver.SurrealConn).Close()
+		})
+		if n := len(srv.LiveQueries()); n != 0 {
+			t.Errorf("%d live queries survived closing", n)
+		}
+		if len(srv.Sent("invalidate")) != 1 {
+			t.Error("session was not invalidated")
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	srv.OnQuery("SELECT * FROM person").Return([]map[string]any{{"id": "person:tobie"}})
+
+	var seen []string
+	rewrite := surrealdbdriver.MiddlewareFuncs{
+		BeforeFunc: func(ctx context.Context, req *api.Request) (context.Context, error) {
+			if params, ok := req.Params.([]any); ok && req.Method == api.APIMethodQuery {
+				params[0] = strings.ReplaceAll(params[0].(string), "people", "person")
+			}
+			return ctx, nil
+		},
+	}
+	observe := surrealdbdriver.MiddlewareFuncs{
+		AfterFunc: func(ctx context.Context, req *api.Request, resp *api.Response, err error, d time.Duration) {
+			if _, ok := surrealdbdriver.ConnFromContext(ctx); !ok {
+				t.Error("connection missing from context")
+			}
+			seen = append(seen, string(req.Method))
+		},
+	}
+	connector, err := surrealdbdriver.NewConnector(
+		surrealdbdriver.WithDSN(srv.DSN()),
+		surrealdbdriver.WithMiddleware(rewrite, observe),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	var id string
+	if err := db.QueryRow("SELECT * FROM people").Scan(&id); err != nil || id != "person:tobie" {
+		t.Fatalf("got %q, %v", id, err)
+	}
+	if strings.Join(seen, ",") != "signin,use,query" {
+		t.Errorf("middleware saw %v", seen)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	srv.OnQuery("SELECT * FROM person").Return([]map[string]any{{"id": "person:tobie"}})
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	connector, err := surrealdbdriver.NewConnector(
+		surrealdbdriver.WithDSN(strings.Replace(srv.DSN(), "root:root", "root:hunter2", 1)),
+		surrealdbdriver.WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	if _, err := db.Exec("SELECT * FROM person"); err != nil {
+		t.Fatal(err)
+	}
+
+	var rpcs []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry["msg"] != "rpc" {
+			continue
+		}
+		rpcs = append(rpcs, entry["method"].(string))
+		if entry["conn_id"] == "" || entry["request_id"] == "" || entry["ns"] != "test" || entry["duration"] == nil {
+			t.Errorf("fields missing: %s", line)
+		}
+	}
+	if strings.Join(rpcs, ",") != "signin,use,query" {
+		t.Errorf("logged %v", rpcs)
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Error("the password was logged")
+	}
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	srv.OnQuery("SELECT * FROM person WHERE name = $_name AND pass = $_password").ReturnStatements(
+		surrealtest.Statement{Result: []any{}, Status: "OK", Time: "350ms"},
+	)
+	srv.OnQuery("SELECT * FROM person").Return([]any{})
+
+	type tenantKey struct{}
+	var buf bytes.Buffer
+	connector, err := surrealdbdriver.NewConnector(
+		surrealdbdriver.WithDSN(srv.DSN()),
+		surrealdbdriver.WithSlowQueryLog(surrealdbdriver.SlowQueryLog{
+			StatementThreshold: 100 * time.Millisecond,
+			Logger:             slog.New(slog.NewJSONHandler(&buf, nil)),
+			Level:              slog.LevelWarn,
+			ContextAttrs: func(ctx context.Context) []slog.Attr {
+				tenant, _ := ctx.Value(tenantKey{}).(string)
+				return []slog.Attr{slog.String("tenant", tenant)}
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	if _, err := db.ExecContext(ctx, "SELECT * FROM person"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() > 0 {
+		t.Fatalf("fast query was logged: %s", buf.String())
+	}
+	_, err = db.ExecContext(ctx, "SELECT * FROM person WHERE name = $_name AND pass = $_password",
+		sql.Named("name", "Tobie"), sql.Named("password", "hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entry struct {
+		Level      string
+		Msg        string
+		Query      string
+		Vars       map[string]string
+		Statements map[string]struct{ Time string }
+		NS, DB     string
+		Tenant     string
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("%v: %s", err, buf.String())
+	}
+	if entry.Level != "WARN" || entry.Msg != "slow query" || entry.NS != "test" || entry.DB != "test" || entry.Tenant != "acme" {
+		t.Errorf("unexpected entry: %s", buf.String())
+	}
+	if entry.Vars["_name"] != "Tobie" || entry.Vars["_password"] != "[REDACTED]" {
+		t.Errorf("vars: %v", entry.Vars)
+	}
+	if entry.Statements["0"].Time != "350ms" {
+		t.Errorf("statements: %v", entry.Statements)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	srv.OnQueryMatch(`^BEGIN TRANSACTION;`).
+		Return([]map[string]any{{"id": "person:tobie", "name": "Tobie"}}).
+		ReturnError("Database record `person:tobie` already exists").
+		Return([]map[string]any{{"name": "Tobie"}, {"name": "Jaime"}})
+
+	db := sql.OpenDB(mustConnector(t, srv))
+	defer db.Close()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	b := &surrealdbdriver.Batch{Transaction: true}
+	b.Queue("CREATE person:tobie SET name = $name", map[string]any{"name": "Tobie"})
+	b.Queue("CREATE person:tobie SET name = $name;", map[string]any{"name": "Tobie again"})
+	b.Queue("SELECT name FROM person WHERE name != '$name' AND name != $name -- not $name", map[string]any{"name": "Tobias"})
+
+	var results *surrealdbdriver.BatchResults
+	err = conn.Raw(func(driverConn any) error {
+		results, err = driverConn.(*surrealdbdriver.SurrealConn).SendBatch(context.Background(), b)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent, vars := srv.Sent("query")[0].Query()
+	want := "BEGIN TRANSACTION;\n" +
+		"CREATE person:tobie SET name = $_batch0_name\n;\n" +
+		"CREATE person:tobie SET name = $_batch1_name\n;\n" +
+		"SELECT name FROM person WHERE name != '$name' AND name != $_batch2_name -- not $name\n;\n" +
+		"COMMIT TRANSACTION;\n"
+	if sent != want {
+		t.Errorf("sent %q", sent)
+	}
+	if vars["_batch0_name"] != "Tobie" || vars["_batch1_name"] != "Tobie again" || vars["_batch2_name"] != "Tobias" {
+		t.Errorf("vars %v", vars)
+	}
+
+	if res, err := results.Exec(); err != nil {
+		t.Error(err)
+	} else if n, _ := res.RowsAffected(); n != 1 {
+		t.Errorf("%d rows affected", n)
+	}
+	if _, err := results.Exec(); api.Classify(err) != api.ErrorClassConflict {
+		t.Errorf("second statement: %v", err)
+	}
+	rows, err := results.Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cols := rows.Columns(); len(cols) != 1 || cols[0] != "name" {
+		t.Errorf("columns %v", cols)
+	}
+	if err := results.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTypedParams(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	srv.OnQueryMatch(`^CREATE`).Return([]any{})
+
+	db := sql.OpenDB(mustConnector(t, srv))
+	defer db.Close()
+
+	at := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	_, err := db.Exec("CREATE event SET at = $_1, took = $_2, by = $_3, note = '$_1', n = $_4",
+		at, st.NewDuration(90*60+1, 500_000_000), st.IntID{Table: "person", Thing: 7}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent, vars := srv.Sent("query")[0].Query()
+	want := "CREATE event SET at = (<datetime>$_1), took = (<duration>$_2), by = type::thing($_3.tb, $_3.id), note = '$_1', n = $_4"
+	if sent != want {
+		t.Errorf("sent %q", sent)
+	}
+	if vars["_1"] != "2024-05-06T07:08:09.123456789Z" || vars["_2"] != "1h30m1s500ms" {
+		t.Errorf("vars %v", vars)
+	}
+	if by, _ := vars["_3"].(map[string]any); by["tb"] != "person" || by["id"] != 7.0 {
+		t.Errorf("record %v", vars["_3"])
+	}
+
+	srv.OnQueryMatch(`^SELECT`).Return([]any{})
+	from, to := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	rows, err := db.Query("SELECT * FROM $_1 WHERE note != '$_1'", st.PrefixBetween("temperature", []any{"london"}, from, to))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	sent, vars = srv.Sent("query")[1].Query()
+	want = "SELECT * FROM temperature:[$_1.begin_0, <datetime>$_1.begin_1]..[$_1.end_0, <datetime>$_1.end_1] WHERE note != '$_1'"
+	if sent != want {
+		t.Errorf("sent %q", sent)
+	}
+	if r, _ := vars["_1"].(map[string]any); r["begin_0"] != "london" || r["end_1"] != "2024-01-02T00:00:00Z" {
+		t.Errorf("range %v", vars["_1"])
+	}
+
+	rows, err = db.Query("SELECT * FROM $_1, $_2", st.NewArrayID("temperature", "london", from), st.NewArrayID("t", "x'); DELETE t; --", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	sent, vars = srv.Sent("query")[2].Query()
+	want = "SELECT * FROM type::thing($_1.tb, [$_1.key_0, <datetime>$_1.key_1]), type::thing($_2.tb, $_2.key)"
+	if sent != want {
+		t.Errorf("sent %q", sent)
+	}
+	if key, _ := vars["_2"].(map[string]any)["key"].([]any); len(key) != 2 || key[0] != "x'); DELETE t; --" {
+		t.Errorf("array key %v", vars["_2"])
+	}
+
+	if _, err := db.Query("SELECT * FROM $_1", st.NewArrayID("t", math.NaN())); err == nil {
+		t.Error("NaN key sent")
+	}
+}
+
+func TestUnbindableParams(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	db := sql.OpenDB(mustConnector(t, srv))
+	defer db.Close()
+
+	minus := -time.Second
+	for _, arg := range []any{make(chan int), func() {}, complex(1, 2), []func(){}, map[string]chan int{}, -time.Second, &minus} {
+		if _, err := db.Exec("RETURN $_1", arg); err == nil {
+			t.Errorf("%T was bound", arg)
+		}
+	}
+	if n := len(srv.Sent("query")); n != 0 {
+		t.Errorf("%d queries reached the server", n)
+	}
+}
+
+func TestNone(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	srv.OnQueryMatch(`^UPDATE`).Return([]any{})
+	srv.OnQuery("SELECT * FROM person").Return([]map[string]any{
+		{"id": "person:1", "nick": "Tobie", "note": nil},
+		{"id": "person:2"},
+	})
+
+	db := sql.OpenDB(mustConnector(t, srv))
+	defer db.Close()
+
+	_, err := db.Exec("UPDATE person:1 SET nick = $_1, note = $_2, age = $_3, born = $_4",
+		st.None, st.Null[string](), st.Some(42), st.Some(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent, vars := srv.Sent("query")[0].Query()
+	if want := "UPDATE person:1 SET nick = NONE, note = $_2, age = $_3, born = (<datetime>$_4)"; sent != want {
+		t.Errorf("sent %q", sent)
+	}
+	if v, ok := vars["_2"]; !ok || v != nil || vars["_3"] != 42.0 {
+		t.Errorf("vars %v", vars)
+	}
+
+	rows, err := db.Query("SELECT * FROM person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var nicks []st.Option[string]
+	for rows.Next() {
+		var id any
+		var nick, note st.Option[string]
+		// Columns are sorted: id, nick, note.
+		if err := rows.Scan(&id, &nick, &note); err != nil {
+			t.Fatal(err)
+		}
+		nicks = append(nicks, nick)
+	}
+	if len(nicks) != 2 {
+		t.Fatalf("%d rows", len(nicks))
+	}
+	if nick, ok := nicks[0].Get(); !ok || nick != "Tobie" {
+		t.Errorf("first nick %v", nicks[0])
+	}
+	// The second record has no nick; it must not keep the first one's.
+	if _, ok := nicks[1].Get(); ok {
+		t.Errorf("second nick %v", nicks[1])
+	}
+
+	var person struct {
+		Nick st.Option[string] `json:"nick"`
+		Note st.Option[string] `json:"note"`
+	}
+	if err := json.Unmarshal([]byte(`{"note": null}`), &person); err != nil {
+		t.Fatal(err)
+	}
+	if !person.Nick.IsNone() || !person.Note.IsNull() {
+		t.Errorf("decoded %v, %v", person.Nick, person.Note)
+	}
+}
+
+func TestDecoding(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	srv.OnQuery("INFO FOR TABLE person").Return(map[string]any{
+		"fields": map[string]any{
+			"born":  "DEFINE FIELD born ON person TYPE option<datetime> PERMISSIONS FULL",
+			"shift": "DEFINE FIELD shift ON person TYPE duration PERMISSIONS FULL",
+			"name":  "DEFINE FIELD name ON person TYPE string PERMISSIONS FULL",
+		},
+	})
+	srv.OnQuery("SELECT * FROM person").Return([]map[string]any{{
+		"id":    "person:tobie",
+		"born":  "1990-01-02T03:04:05.123456789Z",
+		"shift": "1w2d",
+		"name":  "1h",
+	}})
+
+	open := func(t *testing.T, decoding surrealdbdriver.Decoding) *sql.DB {
+		t.Helper()
+		connector, err := surrealdbdriver.NewConnector(surrealdbdriver.WithDSN(srv.DSN()), surrealdbdriver.WithDecoding(decoding))
+		if err != nil {
+			t.Fatal(err)
+		}
+		db := sql.OpenDB(connector)
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+	scan := func(t *testing.T, db *sql.DB) (born, shift, name any) {
+		t.Helper()
+		var id any
+		// Columns are sorted: born, id, name, shift.
+		if err := db.QueryRow("SELECT * FROM person").Scan(&born, &id, &name, &shift); err != nil {
+			t.Fatal(err)
+		}
+		return born, shift, name
+	}
+	infos := func() (n int) {
+		for _, req := range srv.Sent("query") {
+			if sql, _ := req.Query(); sql == "INFO FOR TABLE person" {
+				n++
+			}
+		}
+		return n
+	}
+
+	t.Run("Strings", func(t *testing.T) {
+		born, shift, name := scan(t, open(t, surrealdbdriver.DecodeStrings))
+		if born != "1990-01-02T03:04:05.123456789Z" || shift != "1w2d" || name != "1h" {
+			t.Errorf("got %#v, %#v, %#v", born, shift, name)
+		}
+	})
+	t.Run("Schema", func(t *testing.T) {
+		db := open(t, surrealdbdriver.DecodeSchema)
+		born, shift, name := scan(t, db)
+		if b, ok := born.(time.Time); !ok || b.Nanosecond() != 123456789 {
+			t.Errorf("born %#v", born)
+		}
+		if shift != 9*24*time.Hour || name != "1h" {
+			t.Errorf("got %#v, %#v", shift, name)
+		}
+		scan(t, db)
+		if n := infos(); n != 1 {
+			t.Errorf("schema read %d times", n)
+		}
+	})
+	t.Run("Heuristic", func(t *testing.T) {
+		_, _, name := scan(t, open(t, surrealdbdriver.DecodeHeuristic))
+		if name != time.Hour {
+			t.Errorf("name %#v", name)
+		}
+	})
+}
+
+func mustConnector(t *testing.T, srv *surrealtest.Server) *surrealdbdriver.SurrealConnector {
+	t.Helper()
+	connector, err := surrealdbdriver.NewConnector(surrealdbdriver.WithDSN(srv.DSN()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return connector
+}
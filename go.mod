@@ -3,7 +3,6 @@ module github.com/IngwiePhoenix/surrealdb-driver
 go 1.23.6
 
 require (
-	github.com/clok/kemba v1.2.1
 	github.com/go-rel/rel v0.42.0
 	github.com/go-rel/sql v0.17.0
 	github.com/goccy/go-json v0.10.5
@@ -14,23 +13,17 @@ require (
 	github.com/thoas/go-funk v0.9.3
 	github.com/tidwall/gjson v1.18.0
 	github.com/wI2L/jsondiff v0.6.1
-	github.com/ztrue/tracerr v0.4.0
 	gorm.io/gorm v1.25.12
 )
 
 require (
-	github.com/gookit/color v1.5.4 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
-	github.com/jmoiron/sqlx v1.4.0 // indirect
-	github.com/kr/pretty v0.3.1 // indirect
-	github.com/kr/text v0.2.0 // indirect
-	github.com/rogpeppe/go-internal v1.14.1 // indirect
 	github.com/serenize/snaker v0.0.0-20201027110005-a7ad2135616e // indirect
+	github.com/stretchr/testify v1.10.0 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.1 // indirect
 	github.com/tidwall/sjson v1.2.5 // indirect
-	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
 	golang.org/x/net v0.33.0 // indirect
 	golang.org/x/sys v0.31.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
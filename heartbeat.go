@@ -0,0 +1,80 @@
+package surrealdbdriver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// How long to wait for a pong if nothing else was configured.
+const defaultHeartbeatTimeout = 10 * time.Second
+
+func (c *SurrealConnector) heartbeatTimeout() time.Duration {
+	if c.HeartbeatTimeout > 0 {
+		return c.HeartbeatTimeout
+	}
+	return defaultHeartbeatTimeout
+}
+
+// Pings the server whenever the connection was idle for a whole interval.
+// NAT gateways and load balancers like to silently drop idle connections;
+// without this, we would only notice once a query hangs.
+func (con *SurrealConn) heartbeat(interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-con.done:
+			return
+		case <-ticker.C:
+		}
+
+		idle := time.Since(time.Unix(0, con.lastSeen.Load()))
+		if idle < interval {
+			continue
+		}
+		con.log.Debug("pinging idle connection", "idle", idle)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := con.ping(ctx)
+		cancel()
+		if err != nil {
+			con.markBad(fmt.Errorf("heartbeat failed: %w", err))
+			return
+		}
+	}
+}
+
+// Sends a ping and waits for any pong to arrive.
+func (con *SurrealConn) ping(ctx context.Context) error {
+	if con.WSClient == nil {
+		// Only WebSockets know pings; other transports are taken by their word.
+		return nil
+	}
+	con.pingMu.Lock()
+	defer con.pingMu.Unlock()
+
+	// Forget about a pong that might still be lingering from before.
+	select {
+	case <-con.pong:
+	default:
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultHeartbeatTimeout)
+	}
+	if err := con.WSClient.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+		return err
+	}
+
+	select {
+	case <-con.pong:
+		return nil
+	case <-con.done:
+		return fmt.Errorf("connection closed while waiting for pong")
+	case <-ctx.Done():
+		return fmt.Errorf("no pong received: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,105 @@
+// Package logging holds the logger shared by the driver, surrealtypes,
+// pkg/rel and pkg/gorm. Nothing is logged unless a logger was set.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"sync/atomic"
+)
+
+var current atomic.Pointer[slog.Logger]
+
+func init() {
+	current.Store(Discard())
+}
+
+// Logger returns the package-level logger.
+func Logger() *slog.Logger {
+	return current.Load()
+}
+
+// SetLogger replaces the package-level logger; nil silences it again.
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		current.Store(Discard())
+		return
+	}
+	current.Store(Redacting(l))
+}
+
+// Discard returns a logger that drops everything.
+func Discard() *slog.Logger {
+	return slog.New(discardHandler{})
+}
+
+// Attribute keys whose values are secrets.
+var secretKey = regexp.MustCompile(`(?i)pass(word)?|secret|token|credential|api_?key|authorization`)
+
+// Redacted replaces secret values in log output.
+const Redacted = "[REDACTED]"
+
+// Redacting wraps l so that attributes with secret-sounding keys (password,
+// token, ...) are logged as [REDACTED], no matter who logs them.
+func Redacting(l *slog.Logger) *slog.Logger {
+	if _, ok := l.Handler().(*redactingHandler); ok {
+		return l
+	}
+	if _, ok := l.Handler().(discardHandler); ok {
+		return l
+	}
+	return slog.New(&redactingHandler{next: l.Handler()})
+}
+
+type redactingHandler struct {
+	next slog.Handler
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redact(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redact(a)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name)}
+}
+
+func redact(a slog.Attr) slog.Attr {
+	if secretKey.MatchString(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, g := range group {
+			redacted[i] = redact(g)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	}
+	return a
+}
+
+// A slog.Handler that drops everything; slog.DiscardHandler needs Go 1.24.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
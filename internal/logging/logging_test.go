@@ -0,0 +1,31 @@
+package logging_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/IngwiePhoenix/surrealdb-driver/internal/logging"
+)
+
+func TestRedacting(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.Redacting(slog.New(slog.NewTextHandler(&buf, nil)))
+	logger.With("token", "abc").Info("signin",
+		"user", "root",
+		"Password", "hunter2",
+		slog.Group("creds", "api_key", "xyz", "ns", "test"),
+	)
+	out := buf.String()
+	for _, secret := range []string{"abc", "hunter2", "xyz"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("%q was logged: %s", secret, out)
+		}
+	}
+	for _, kept := range []string{"user=root", "creds.ns=test"} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("%q is missing: %s", kept, out)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package surrealdbdriver
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/IngwiePhoenix/surrealdb-driver/api"
+	"github.com/gofrs/uuid/v5"
+	"github.com/tidwall/gjson"
+)
+
+// How many notifications may queue up per live query before new ones are
+// dropped. The reader must never block on a slow consumer.
+const liveBufferSize = 64
+
+// Statements that start a live query when sent through `query`.
+var liveStatement = regexp.MustCompile(`(?i)\bLIVE\s+SELECT\b`)
+
+// Live starts a live query on a table and returns its ID together with a
+// channel of notifications. The channel is closed once the live query is
+// killed or the connection is closed.
+func (con *SurrealConn) Live(ctx context.Context, table string, diff bool) (string, <-chan *api.LiveNotificationResponse, error) {
+	res, err := con.execObj(ctx, con.Caller.CallLive(table, diff))
+	if err != nil {
+		return "", nil, err
+	}
+	id := res.Result.String()
+	return id, con.subscribe(id), nil
+}
+
+// Subscribe returns the notifications for a live query that was started
+// through a `LIVE SELECT` statement on this connection.
+func (con *SurrealConn) Subscribe(id string) <-chan *api.LiveNotificationResponse {
+	return con.subscribe(id)
+}
+
+// Kill stops a live query.
+func (con *SurrealConn) Kill(ctx context.Context, id string) error {
+	_, err := con.execObj(ctx, con.Caller.CallKill(id))
+	return err
+}
+
+// LiveQueries lists the IDs of live queries started on this connection that
+// have not been killed yet.
+func (con *SurrealConn) LiveQueries() []string {
+	con.liveMu.Lock()
+	defer con.liveMu.Unlock()
+	out := make([]string, 0, len(con.lives))
+	for id := range con.lives {
+		out = append(out, id)
+	}
+	return out
+}
+
+func (con *SurrealConn) subscribe(id string) <-chan *api.LiveNotificationResponse {
+	con.liveMu.Lock()
+	defer con.liveMu.Unlock()
+	ch, ok := con.lives[id]
+	if !ok || ch == nil {
+		ch = make(chan *api.LiveNotificationResponse, liveBufferSize)
+		con.lives[id] = ch
+	}
+	if !ok {
+		con.countLive(1)
+	}
+	return ch
+}
+
+// Starts tracking a live query without a subscriber. Callers hold liveMu.
+func (con *SurrealConn) addLive(id string) {
+	if _, ok := con.lives[id]; !ok {
+		con.lives[id] = nil
+		con.countLive(1)
+	}
+}
+
+func (con *SurrealConn) countLive(delta int) {
+	if m := con.metrics(); m != nil && delta != 0 {
+		m.LiveQueries(delta)
+	}
+}
+
+// Keeps count of live queries as they are started and killed, no matter if
+// that happened through the dedicated RPCs or through SurrealQL.
+func (con *SurrealConn) trackLive(req *api.Request, res *api.Response) {
+	switch req.Method {
+	case api.APIMethodLive:
+		con.liveMu.Lock()
+		con.addLive(res.Result.String())
+		con.liveMu.Unlock()
+	case api.APIMethodKill:
+		if ids, ok := req.Params.([]string); ok && len(ids) == 1 {
+			con.untrackLive(ids[0])
+		}
+	case api.APIMethodQuery:
+		params, ok := req.Params.([]any)
+		if !ok || len(params) == 0 {
+			return
+		}
+		sql, _ := params[0].(string)
+		if !liveStatement.MatchString(sql) {
+			return
+		}
+		// A LIVE SELECT yields the query's UUID. Other statements in the
+		// same query might do so, too - killing those will merely fail.
+		res.Result.ForEach(func(_, stmt gjson.Result) bool {
+			result := stmt.Get("result")
+			if stmt.Get("status").String() == "OK" && result.Type == gjson.String {
+				if _, err := uuid.FromString(result.String()); err == nil {
+					con.liveMu.Lock()
+					con.addLive(result.String())
+					con.liveMu.Unlock()
+				}
+			}
+			return true
+		})
+	}
+}
+
+func (con *SurrealConn) untrackLive(id string) {
+	con.liveMu.Lock()
+	defer con.liveMu.Unlock()
+	if ch, ok := con.lives[id]; ok {
+		if ch != nil {
+			close(ch)
+		}
+		delete(con.lives, id)
+		con.countLive(-1)
+	}
+}
+
+// Routes a live notification to its subscriber. Notifications nobody asked
+// for are dropped.
+func (con *SurrealConn) dispatchLive(msg []byte) error {
+	result := gjson.GetBytes(msg, "result")
+	if !result.IsObject() || !result.Get("id").Exists() {
+		return errors.New("not a live notification")
+	}
+	n := &api.LiveNotificationResponse{
+		Action: result.Get("action").String(),
+		Id:     result.Get("id").String(),
+		Result: result.Get("result"),
+	}
+	con.liveMu.Lock()
+	defer con.liveMu.Unlock()
+	ch := con.lives[n.Id]
+	if ch == nil {
+		return nil
+	}
+	select {
+	case ch <- n:
+		return nil
+	default:
+		return errors.New("live query " + n.Id + " is not being consumed; dropped a notification")
+	}
+}
@@ -0,0 +1,67 @@
+package surrealdbdriver
+
+import (
+	"errors"
+	"time"
+
+	"github.com/IngwiePhoenix/surrealdb-driver/api"
+	"github.com/tidwall/gjson"
+)
+
+// Metrics receives measurements from the connector and its connections.
+// Implementations must be safe for concurrent use and should be cheap; they
+// are called on the path of every request.
+//
+// See the surrealmetrics (expvar) and surrealprom (Prometheus) packages for
+// adapters.
+type Metrics interface {
+	// A request is about to be sent; it counts as in flight until
+	// RequestDone.
+	RequestStarted(method api.APIMethod)
+	// A request finished after d. class is api.ErrorClassNone if it
+	// succeeded.
+	RequestDone(method api.APIMethod, d time.Duration, class api.ErrorClass)
+	// A statement of a query finished, taking d as reported by SurrealDB.
+	StatementDone(d time.Duration, class api.ErrorClass)
+	// A connection was opened, or failed to open if class is set.
+	// reconnect tells if it replaces a connection that went bad.
+	Connected(class api.ErrorClass, reconnect bool)
+	// A connection turned out to be unusable.
+	ConnectionBad()
+	// The number of running live queries changed by delta.
+	LiveQueries(delta int)
+	// Bytes were written to or read from a transport.
+	Transferred(sent, received int)
+}
+
+// WithMetrics reports measurements to m.
+func WithMetrics(m Metrics) Option {
+	return func(c *SurrealConnector) error {
+		if m == nil {
+			return errors.New("metrics must not be nil")
+		}
+		c.Metrics = m
+		return nil
+	}
+}
+
+// The connector's Metrics, or nil.
+func (con *SurrealConn) metrics() Metrics {
+	if con.connector == nil {
+		return nil
+	}
+	return con.connector.Metrics
+}
+
+// Reports the statements of a query response.
+func observeStatements(m Metrics, res *api.Response) {
+	res.Result.ForEach(func(_, stmt gjson.Result) bool {
+		d, _ := time.ParseDuration(stmt.Get("time").String())
+		class := api.ErrorClassNone
+		if stmt.Get("status").String() != "OK" {
+			class = api.Classify(&api.QueryError{Message: stmt.Get("result").String()})
+		}
+		m.StatementDone(d, class)
+		return true
+	})
+}
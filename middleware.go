@@ -0,0 +1,102 @@
+package surrealdbdriver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IngwiePhoenix/surrealdb-driver/api"
+)
+
+// Middleware is wrapped around every RPC a connection makes, including
+// signing in and the ones sent while shutting down.
+//
+// Before runs before the request is sent. It may rewrite req in place (but
+// should leave its ID alone) and return a derived context, i.e. carrying a
+// span; that context is the one handed to its After. Returning an error
+// aborts the request.
+//
+// After runs once the response arrived or the request failed. For queries,
+// resp may be set even though err is not nil: err then holds the errors of
+// the failed statements. d is the time spent sending and waiting.
+//
+// Before runs outermost first, After innermost first.
+type Middleware interface {
+	Before(ctx context.Context, req *api.Request) (context.Context, error)
+	After(ctx context.Context, req *api.Request, resp *api.Response, err error, d time.Duration)
+}
+
+// MiddlewareFuncs turns a pair of functions into a Middleware. Either may be
+// nil.
+type MiddlewareFuncs struct {
+	BeforeFunc func(ctx context.Context, req *api.Request) (context.Context, error)
+	AfterFunc  func(ctx context.Context, req *api.Request, resp *api.Response, err error, d time.Duration)
+}
+
+func (m MiddlewareFuncs) Before(ctx context.Context, req *api.Request) (context.Context, error) {
+	if m.BeforeFunc == nil {
+		return ctx, nil
+	}
+	return m.BeforeFunc(ctx, req)
+}
+
+func (m MiddlewareFuncs) After(ctx context.Context, req *api.Request, resp *api.Response, err error, d time.Duration) {
+	if m.AfterFunc != nil {
+		m.AfterFunc(ctx, req, resp, err, d)
+	}
+}
+
+// WithMiddleware adds middleware to every connection. Middleware given
+// earlier wraps middleware given later.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *SurrealConnector) error {
+		for _, m := range mw {
+			if m == nil {
+				return errors.New("middleware must not be nil")
+			}
+		}
+		c.Middleware = append(c.Middleware, mw...)
+		return nil
+	}
+}
+
+type connKey struct{}
+
+// ConnFromContext returns the connection an RPC is made on. It is meant for
+// middleware and only set within it.
+func ConnFromContext(ctx context.Context) (*SurrealConn, bool) {
+	con, ok := ctx.Value(connKey{}).(*SurrealConn)
+	return con, ok
+}
+
+func runMiddleware(
+	ctx context.Context,
+	con *SurrealConn,
+	mw []Middleware,
+	req *api.Request,
+	next func(context.Context, *api.Request) (*api.Response, error),
+) (*api.Response, error) {
+	ctx = context.WithValue(ctx, connKey{}, con)
+	ctxs := make([]context.Context, 0, len(mw))
+	start := time.Now()
+	for _, m := range mw {
+		mctx, err := m.Before(ctx, req)
+		if err != nil {
+			// The ones that ran already get to see the failure.
+			for i := len(ctxs) - 1; i >= 0; i-- {
+				mw[i].After(ctxs[i], req, nil, err, time.Since(start))
+			}
+			return nil, err
+		}
+		ctx = mctx
+		ctxs = append(ctxs, mctx)
+	}
+
+	start = time.Now()
+	resp, err := next(ctx, req)
+	d := time.Since(start)
+	for i := len(mw) - 1; i >= 0; i-- {
+		mw[i].After(ctxs[i], req, resp, err, d)
+	}
+	return resp, err
+}
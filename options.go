@@ -0,0 +1,463 @@
+package surrealdbdriver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/IngwiePhoenix/surrealdb-driver/config"
+	"github.com/IngwiePhoenix/surrealdb-driver/internal/logging"
+	"github.com/gorilla/websocket"
+)
+
+// The WebSocket sub-protocols SurrealDB understands. Only JSON is implemented
+// by this driver; CBOR would require a whole different (un)marshalling path.
+const (
+	ProtocolJSON = "json"
+	ProtocolCBOR = "cbor"
+)
+
+// Option configures a SurrealConnector. Options are applied in order, so a
+// later option overrides an earlier one - this is also how a DSN passed via
+// WithDSN can be amended afterwards.
+type Option func(*SurrealConnector) error
+
+// Hooks are called on connection lifecycle events. Every field is optional.
+type Hooks struct {
+	// Called before dialing. The returned context is used for dialing and
+	// signing in, and handed to OnConnect or OnConnectError.
+	BeforeConnect func(ctx context.Context) context.Context
+	// Called once a connection is established and fully signed in.
+	OnConnect func(ctx context.Context, conn *SurrealConn)
+	// Called when dialing, signing in or preparing the session failed.
+	OnConnectError func(ctx context.Context, err error)
+	// Called right before a connection is closed.
+	OnClose func(conn *SurrealConn)
+	// Called once when a connection turned out to be unusable, i.e. because
+	// the heartbeat went unanswered or the socket broke.
+	OnBadConn func(conn *SurrealConn, cause error)
+}
+
+// NewConnector creates a connector that can be handed to sql.OpenDB:
+//
+//	c, err := surrealdbdriver.NewConnector(
+//		surrealdbdriver.WithEndpoint("ws://localhost:8000/rpc"),
+//		surrealdbdriver.WithRootAuth("root", "root"),
+//		surrealdbdriver.WithNamespace("app", "app"),
+//	)
+//	db := sql.OpenDB(c)
+func NewConnector(opts ...Option) (*SurrealConnector, error) {
+	c := &SurrealConnector{
+		Creds:    &config.Credentials{Method: config.AuthMethodAnonymous},
+		Dialer:   &websocket.Dialer{Proxy: http.ProxyFromEnvironment},
+		Headers:  http.Header{},
+		Protocol: ProtocolJSON,
+		driver:   SurrealDBDriver,
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.Creds.URL == nil {
+		return nil, errors.New("no endpoint specified")
+	}
+	return c, nil
+}
+
+// WithDSN applies everything that can be expressed in a DSN as accepted by
+// config.ParseUrl. This is what sql.Open uses under the hood.
+func WithDSN(dsn string) Option {
+	return func(c *SurrealConnector) error {
+		creds, err := config.ParseUrl(dsn)
+		if err != nil {
+			return err
+		}
+		for _, opt := range dsnOptions(creds) {
+			if err := opt(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Translates parsed DSN credentials into the equivalent options.
+func dsnOptions(creds *config.Credentials) []Option {
+	opts := []Option{
+		WithCredentials(creds),
+	}
+	if creds.HasTLS() {
+		opts = append(opts, func(c *SurrealConnector) error {
+			cfg, err := creds.TLSConfig()
+			if err != nil {
+				return err
+			}
+			return WithTLSConfig(cfg)(c)
+		})
+	}
+	if creds.Proxy != nil {
+		opts = append(opts, WithProxyURL(creds.Proxy))
+	}
+	if creds.ConnectTimeout > 0 {
+		opts = append(opts, WithDialTimeout(creds.ConnectTimeout))
+	}
+	if creds.ReadTimeout > 0 {
+		opts = append(opts, WithReadTimeout(creds.ReadTimeout))
+	}
+	if creds.WriteTimeout > 0 {
+		opts = append(opts, WithWriteTimeout(creds.WriteTimeout))
+	}
+	if creds.MaxMessageSize > 0 {
+		opts = append(opts, WithMaxMessageSize(creds.MaxMessageSize))
+	}
+	if creds.HeartbeatInterval > 0 {
+		opts = append(opts, WithHeartbeat(creds.HeartbeatInterval, creds.HeartbeatTimeout))
+	}
+	if creds.Compression {
+		opts = append(opts, WithCompression(true))
+	}
+	if creds.Protocol != "" {
+		opts = append(opts, WithProtocol(creds.Protocol))
+	}
+	if creds.Decoding != "" {
+		opts = append(opts, func(c *SurrealConnector) error {
+			decoding, err := ParseDecoding(creds.Decoding)
+			if err != nil {
+				return err
+			}
+			return WithDecoding(decoding)(c)
+		})
+	}
+	return opts
+}
+
+// WithEndpoint sets the RPC endpoint, i.e. ws://localhost:8000/rpc
+func WithEndpoint(endpoint string) Option {
+	return func(c *SurrealConnector) error {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+		switch u.Scheme {
+		case "ws", "wss":
+		default:
+			return errors.New("endpoint must use ws:// or wss://, got: " + u.Scheme)
+		}
+		c.Creds.URL = u
+		return nil
+	}
+}
+
+// WithCredentials replaces all credentials at once. The endpoint is kept if
+// creds does not carry one itself.
+func WithCredentials(creds *config.Credentials) Option {
+	return func(c *SurrealConnector) error {
+		if creds == nil {
+			return errors.New("credentials must not be nil")
+		}
+		cp := *creds
+		if cp.URL == nil {
+			cp.URL = c.Creds.URL
+		}
+		c.Creds = &cp
+		return nil
+	}
+}
+
+// WithRootAuth signs in as a root user.
+func WithRootAuth(username, password string) Option {
+	return func(c *SurrealConnector) error {
+		c.Creds.Method = config.AuthMethodRoot
+		c.Creds.Username = username
+		c.Creds.Password = password
+		return nil
+	}
+}
+
+// WithDatabaseAuth signs in as a database user; this implies WithNamespace.
+func WithDatabaseAuth(ns, db, username, password string) Option {
+	return func(c *SurrealConnector) error {
+		c.Creds.Method = config.AuthMethodDB
+		c.Creds.Namespace = ns
+		c.Creds.Database = db
+		c.Creds.Username = username
+		c.Creds.Password = password
+		return nil
+	}
+}
+
+// WithRecordAuth signs in through a record access method; this implies WithNamespace.
+func WithRecordAuth(ns, db, ac, username, password string) Option {
+	return func(c *SurrealConnector) error {
+		c.Creds.Method = config.AuthMethodRecord
+		c.Creds.Namespace = ns
+		c.Creds.Database = db
+		c.Creds.AccessControl = ac
+		c.Creds.Username = username
+		c.Creds.Password = password
+		return nil
+	}
+}
+
+// WithToken authenticates with an already issued token.
+func WithToken(token string) Option {
+	return func(c *SurrealConnector) error {
+		c.Creds.Method = config.AuthMethodToken
+		c.Creds.Token = token
+		return nil
+	}
+}
+
+// WithNamespace selects the namespace and database after signing in.
+func WithNamespace(ns, db string) Option {
+	return func(c *SurrealConnector) error {
+		c.Creds.Namespace = ns
+		c.Creds.Database = db
+		return nil
+	}
+}
+
+// WithDialer replaces the WebSocket dialer entirely. Options that tweak the
+// dialer (TLS, proxy, compression) should be applied after this one.
+func WithDialer(dialer *websocket.Dialer) Option {
+	return func(c *SurrealConnector) error {
+		if dialer == nil {
+			return errors.New("dialer must not be nil")
+		}
+		c.Dialer = dialer
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used for wss:// endpoints.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *SurrealConnector) error {
+		c.Dialer.TLSClientConfig = cfg
+		return nil
+	}
+}
+
+// WithRootCAs sets the pool of certificate authorities the server
+// certificate is verified against.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(c *SurrealConnector) error {
+		c.tlsConfig().RootCAs = pool
+		return nil
+	}
+}
+
+// WithClientCertificate presents a client certificate (mTLS).
+func WithClientCertificate(cert tls.Certificate) Option {
+	return func(c *SurrealConnector) error {
+		cfg := c.tlsConfig()
+		cfg.Certificates = append(cfg.Certificates, cert)
+		return nil
+	}
+}
+
+// WithTLSServerName overrides the name the server certificate is verified for.
+func WithTLSServerName(name string) Option {
+	return func(c *SurrealConnector) error {
+		c.tlsConfig().ServerName = name
+		return nil
+	}
+}
+
+// WithInsecureSkipVerify disables server certificate verification. Only ever
+// use this for testing.
+func WithInsecureSkipVerify(skip bool) Option {
+	return func(c *SurrealConnector) error {
+		c.tlsConfig().InsecureSkipVerify = skip
+		return nil
+	}
+}
+
+// WithTLSFiles loads a CA and/or a client certificate and key from disk, just
+// like the tls_ca, tls_cert and tls_key DSN parameters do. Empty paths are
+// skipped.
+func WithTLSFiles(caFile, certFile, keyFile string) Option {
+	return func(c *SurrealConnector) error {
+		loaded, err := (&config.Credentials{
+			TLSCA:   caFile,
+			TLSCert: certFile,
+			TLSKey:  keyFile,
+		}).TLSConfig()
+		if err != nil {
+			return err
+		}
+		cfg := c.tlsConfig()
+		if loaded.RootCAs != nil {
+			cfg.RootCAs = loaded.RootCAs
+		}
+		cfg.Certificates = append(cfg.Certificates, loaded.Certificates...)
+		return nil
+	}
+}
+
+// WithDialTimeout bounds the time it takes to open the WebSocket, including
+// the TLS and protocol handshake.
+func WithDialTimeout(d time.Duration) Option {
+	return func(c *SurrealConnector) error {
+		c.DialTimeout = d
+		c.Dialer.HandshakeTimeout = d
+		return nil
+	}
+}
+
+// WithReadTimeout bounds how long a request waits for its response. A
+// connection that ran into this timeout is discarded.
+func WithReadTimeout(d time.Duration) Option {
+	return func(c *SurrealConnector) error {
+		c.ReadTimeout = d
+		return nil
+	}
+}
+
+// WithWriteTimeout bounds how long sending a single request may take.
+func WithWriteTimeout(d time.Duration) Option {
+	return func(c *SurrealConnector) error {
+		c.WriteTimeout = d
+		return nil
+	}
+}
+
+// WithMaxMessageSize limits the size of a single response, in bytes. Larger
+// responses fail the request and discard the connection.
+func WithMaxMessageSize(size int64) Option {
+	return func(c *SurrealConnector) error {
+		if size < 0 {
+			return errors.New("max message size must not be negative")
+		}
+		c.MaxMessageSize = size
+		return nil
+	}
+}
+
+// WithHeartbeat pings connections that were idle for interval and discards
+// them if no pong arrives within timeout (10s if zero). This catches half-open
+// connections before a query runs into them.
+func WithHeartbeat(interval, timeout time.Duration) Option {
+	return func(c *SurrealConnector) error {
+		if interval < 0 || timeout < 0 {
+			return errors.New("heartbeat interval and timeout must not be negative")
+		}
+		c.HeartbeatInterval = interval
+		c.HeartbeatTimeout = timeout
+		return nil
+	}
+}
+
+// WithCloseTimeout bounds how long Close() may take to drain requests in
+// flight, kill live queries and say goodbye (5s if zero). Use
+// SurrealConn.Shutdown to pass a context instead.
+func WithCloseTimeout(timeout time.Duration) Option {
+	return func(c *SurrealConnector) error {
+		if timeout < 0 {
+			return errors.New("close timeout must not be negative")
+		}
+		c.CloseTimeout = timeout
+		return nil
+	}
+}
+
+// WithHeaders adds extra HTTP headers to the WebSocket handshake.
+func WithHeaders(headers http.Header) Option {
+	return func(c *SurrealConnector) error {
+		for k, vs := range headers {
+			for _, v := range vs {
+				c.Headers.Add(k, v)
+			}
+		}
+		return nil
+	}
+}
+
+// WithProxy sets the function used to pick a proxy for the handshake. By
+// default, the environment (HTTP_PROXY, HTTPS_PROXY, ...) is respected.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(c *SurrealConnector) error {
+		c.Dialer.Proxy = proxy
+		return nil
+	}
+}
+
+// WithProxyURL routes all connections through a fixed proxy.
+func WithProxyURL(proxy *url.URL) Option {
+	return WithProxy(http.ProxyURL(proxy))
+}
+
+// WithCompression toggles per-message compression.
+func WithCompression(enabled bool) Option {
+	return func(c *SurrealConnector) error {
+		c.Dialer.EnableCompression = enabled
+		return nil
+	}
+}
+
+// WithLogger sets the logger used by the connector and its connections,
+// instead of the one set via SetLogger. Secrets are redacted, see SetLogger.
+// Passing nil goes back to the package-level logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *SurrealConnector) error {
+		if logger != nil {
+			logger = logging.Redacting(logger)
+		}
+		c.logger = logger
+		return nil
+	}
+}
+
+// WithHooks sets the lifecycle hooks.
+func WithHooks(hooks Hooks) Option {
+	return func(c *SurrealConnector) error {
+		c.Hooks = hooks
+		return nil
+	}
+}
+
+// WithSessionVars defines variables (via `let`) on every new connection, so
+// they can be referenced as $name in every query.
+func WithSessionVars(vars map[string]any) Option {
+	return func(c *SurrealConnector) error {
+		if c.SessionVars == nil {
+			c.SessionVars = map[string]any{}
+		}
+		for k, v := range vars {
+			c.SessionVars[k] = v
+		}
+		return nil
+	}
+}
+
+// WithProtocol selects the WebSocket sub-protocol. Only ProtocolJSON is
+// currently supported.
+func WithProtocol(protocol string) Option {
+	return func(c *SurrealConnector) error {
+		switch protocol {
+		case ProtocolJSON:
+			c.Protocol = protocol
+			return nil
+		case ProtocolCBOR:
+			return errors.New("the cbor protocol is not supported by this driver")
+		default:
+			return errors.New("unknown protocol: " + protocol)
+		}
+	}
+}
+
+// Returns the dialer's TLS config for modification. It is cloned first, so
+// that a config passed to WithTLSConfig is never changed behind the user's back.
+func (c *SurrealConnector) tlsConfig() *tls.Config {
+	if c.Dialer.TLSClientConfig == nil {
+		c.Dialer.TLSClientConfig = &tls.Config{}
+	} else {
+		c.Dialer.TLSClientConfig = c.Dialer.TLSClientConfig.Clone()
+	}
+	return c.Dialer.TLSClientConfig
+}
@@ -0,0 +1,160 @@
+package surrealdbdriver
+
+import (
+	"database/sql/driver"
+	"strconv"
+	"strings"
+
+	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
+)
+
+// A parameter bound as a SurrealDB type of its own: value is sent, expr
+// turns it back into the type, see st.SurrealParam.
+type typedParam struct {
+	expr  string
+	value any
+}
+
+// Returns v as the SurrealDB type it stands for, see st.BindParam, or v
+// itself if JSON carries it faithfully.
+func encodeParam(v any) any {
+	if p, ok := v.(typedParam); ok {
+		return p
+	}
+	expr, value := st.BindParam(v)
+	if expr == "$" {
+		return value
+	}
+	return typedParam{expr: expr, value: value}
+}
+
+// Binds the variables of a query: typed ones are sent as their JSON value and
+// every use of them in sql is replaced by the expression restoring their
+// type. Casts are put in parentheses, (<datetime>$_1), so that they bind
+// the same next to any operator.
+func bindParams(sql string, vars map[string]any) (string, map[string]any) {
+	var exprs map[string]string
+	for name, v := range vars {
+		p, ok := encodeParam(v).(typedParam)
+		if !ok {
+			continue
+		}
+		if exprs == nil {
+			exprs = map[string]string{}
+			vars = copyVars(vars)
+		}
+		expr := rewriteVars(p.expr, func(v string) (string, bool) {
+			// A lone $ stands for the variable; strings in expr stay as
+			// they are.
+			return "$" + name, v == ""
+		})
+		if strings.HasPrefix(expr, "<") {
+			expr = "(" + expr + ")"
+		}
+		exprs[name] = expr
+		vars[name] = p.value
+	}
+	if exprs == nil {
+		return sql, vars
+	}
+	return rewriteVars(sql, func(name string) (string, bool) {
+		expr, ok := exprs[name]
+		return expr, ok
+	}), vars
+}
+
+// The caller's map is left alone; it might be reused.
+func copyVars(vars map[string]any) map[string]any {
+	out := make(map[string]any, len(vars))
+	for name, v := range vars {
+		out[name] = v
+	}
+	return out
+}
+
+// Names positional arguments $_1, $_2, ... and named ones $_name.
+func namedArgs(args []driver.NamedValue) map[string]any {
+	out := make(map[string]any, len(args))
+	for _, v := range args {
+		if v.Name == "" {
+			out["_"+strconv.Itoa(v.Ordinal)] = v.Value
+		} else {
+			out["_"+v.Name] = v.Value
+		}
+	}
+	return out
+}
+
+// Names arguments $_1, $_2, ...
+func valueArgs(args []driver.Value) map[string]any {
+	out := make(map[string]any, len(args))
+	for i, v := range args {
+		out["_"+strconv.Itoa(i+1)] = v
+	}
+	return out
+}
+
+// Replaces the $variables in sql that replace knows, leaving strings,
+// escaped identifiers and comments alone.
+func rewriteVars(sql string, replace func(name string) (string, bool)) string {
+	out := strings.Builder{}
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(sql) && sql[end] != c {
+				if sql[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(sql))
+			out.WriteString(sql[i:end])
+			i = end - 1
+		case strings.HasPrefix(sql[i:], "⟨"):
+			end := strings.Index(sql[i:], "⟩")
+			if end < 0 {
+				end = len(sql) - i
+			} else {
+				end += len("⟩")
+			}
+			out.WriteString(sql[i : i+end])
+			i += end - 1
+		case c == '-' && strings.HasPrefix(sql[i:], "--"), c == '/' && strings.HasPrefix(sql[i:], "//"), c == '#':
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			out.WriteString(sql[i : i+end])
+			i += end - 1
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i
+			} else {
+				end += 4
+			}
+			out.WriteString(sql[i : i+end])
+			i += end - 1
+		case c == '$':
+			end := i + 1
+			for end < len(sql) && isVarChar(sql[end]) {
+				end++
+			}
+			if to, ok := replace(sql[i+1 : end]); ok {
+				out.WriteString(to)
+			} else {
+				out.WriteString(sql[i:end])
+			}
+			i = end - 1
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+func isVarChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
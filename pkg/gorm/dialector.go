@@ -3,10 +3,14 @@ package gorm
 import (
 	"database/sql"
 	"github.com/goccy/go-json"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/IngwiePhoenix/surrealdb-driver/internal/logging"
 	sdbClause "github.com/IngwiePhoenix/surrealdb-driver/pkg/gorm/clauses"
+	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/callbacks"
 	gormClause "gorm.io/gorm/clause"
@@ -48,8 +52,12 @@ var _ (gorm.Dialector) = (*SurrealDialector)(nil)
 //var _ (gorm.ConnPool) = (*driver.SurrealConn)(nil)
 
 func Open(url string) gorm.Dialector {
+	return &SurrealDialector{SurrealGormConfig: &SurrealGormConfig{Url: url}}
+}
 
-	return &Dialector{Config: &Config{DSN: dsn, DSNConfig: dsnConf}}
+// New uses an existing connection pool, i.e. a *sql.DB.
+func New(conn gorm.ConnPool) gorm.Dialector {
+	return &SurrealDialector{SurrealGormConfig: &SurrealGormConfig{}, Conn: conn}
 }
 
 func (SurrealDialector) Name() string {
@@ -92,6 +100,11 @@ func (dialector SurrealDialector) Initialize(db *gorm.DB) error {
 		callbackConfig.DeleteClauses = append(callbackConfig.DeleteClauses, "RETURN AFTER")
 	}
 	callbacks.RegisterDefaultCallbacks(db, callbackConfig)
+	logging.Logger().Debug("gorm: dialector initialized",
+		"driver", dialector.DriverName,
+		"always_return", dialector.AlwaysReturn,
+		"own_pool", dialector.Conn == nil,
+	)
 
 	// Overwriting special instructions:
 
@@ -158,16 +171,18 @@ func (dialector SurrealDialector) DataTypeOf(field *schema.Field) string {
 	// TODO: Unaccounted types:
 	//         SurrealDB | Go            | Note
 	//       - arrays    | []any         |
-	//       - duration  | time.Duration |
 	//       - object    | interface{}   |
 	//       - literal   | any?          |
-	//       - option    | option[T]     |
 	//       - range     |               |
-	//       - record    | T             | specific type needed
-	//                   |               | supports constraint
-	//                   |               | i.e.: record<T [| T2 [|...]]>
 	//       - set       | make(T, N)    |
 	// TODO: decimal or float...? Probably a config value...?
+
+	// surrealtypes and time.Duration have a type of their own; GORM would
+	// go by what their Value returns, i.e. string for a st.Decimal or
+	// bytes for a st.ID[T], which is record<T>.
+	if t := field.IndirectFieldType; t != nil && (t.PkgPath() == surrealtypesPath || t == durationType) {
+		return st.SurrealKind(t)
+	}
 	switch field.DataType {
 	case schema.Bool:
 		return "bool"
@@ -186,6 +201,11 @@ func (dialector SurrealDialector) DataTypeOf(field *schema.Field) string {
 	}
 }
 
+var (
+	surrealtypesPath = reflect.TypeOf(st.Decimal{}).PkgPath()
+	durationType     = reflect.TypeOf(time.Duration(0))
+)
+
 func (dialector SurrealDialector) getSchemaCustomType(field *schema.Field) string {
 	// TODO: Record
 	sqlType := string(field.DataType)
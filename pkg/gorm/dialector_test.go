@@ -0,0 +1,55 @@
+package gorm_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	sgorm "github.com/IngwiePhoenix/surrealdb-driver/pkg/gorm"
+	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
+	"gorm.io/gorm/schema"
+)
+
+type Users struct{}
+
+func (Users) TableName() string { return "users" }
+
+type Invoice struct {
+	ID      string
+	Owner   st.ID[Users]
+	Link    st.IntID
+	Total   st.Decimal
+	Due     st.DateTime
+	Paid    st.NullDateTime
+	Term    st.Duration
+	Grace   time.Duration
+	Note    st.Option[string]
+	Place   st.Point
+	Count   int
+	Created time.Time
+}
+
+func TestDataTypeOf(t *testing.T) {
+	s, err := schema.Parse(&Invoice{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dialector := sgorm.New(nil)
+	for name, want := range map[string]string{
+		"Owner":   "record<users>",
+		"Link":    "record",
+		"Total":   "decimal",
+		"Due":     "datetime",
+		"Paid":    "datetime | null",
+		"Term":    "duration",
+		"Grace":   "duration",
+		"Note":    "option<string>",
+		"Place":   "geometry<point>",
+		"Count":   "int",
+		"Created": "datetime",
+	} {
+		if got := dialector.DataTypeOf(s.LookUpField(name)); got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+}
@@ -14,7 +14,7 @@ type User struct {
 }
 
 func main() {
-	db, _ := gorm.Open(surrealdb.Open(), &gorm.Config{})
+	db, _ := gorm.Open(surrealdb.Open("ws://root:root@localhost:8000/rpc?method=root&ns=test&db=test"), &gorm.Config{})
 
 	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
 		return tx.Model(&User{}).Where("name = ?", "Alice").Find(&User{})
@@ -62,7 +62,7 @@ func (i Insert) WriteValues(buffer *builder.Buffer, mutates map[string]rel.Mutat
 			if n > 0 {
 				buffer.WriteString(", ")
 			}
-			buffer.WriteValue(mut.Value)
+			writeValue(buffer, mut.Value)
 			n = n + 1
 		}
 	}
@@ -101,7 +101,7 @@ func (i Insert) WriteOnConflict(buffer *builder.Buffer, mutates map[string]rel.M
 				buffer.WriteValue(mutate.Value)
 			case rel.ChangeSetOp:
 				buffer.WriteString(" = ")
-				buffer.WriteValue(mutate.Value)
+				writeValue(buffer, mutate.Value)
 			case rel.ChangeIncOp:
 				// TODO: "inc" probably isn't just... this.
 				buffer.WriteString(" += ")
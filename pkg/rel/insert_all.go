@@ -54,7 +54,7 @@ func (ia InsertAll) WriteValues(buffer *builder.Buffer, fields []string, bulkMut
 
 		for j, field := range fields {
 			if mut, ok := mutates[field]; ok && mut.Type == rel.ChangeSetOp {
-				buffer.WriteValue(mut.Value)
+				writeValue(buffer, mut.Value)
 			} else {
 				// TODO: There is no real way to statically denote a default.
 				//       So, let's hope this works?
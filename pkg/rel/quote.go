@@ -1,11 +1,12 @@
 package rel
 
 import (
-	"github.com/goccy/go-json"
 	"fmt"
 	"strings"
 
+	"github.com/IngwiePhoenix/surrealdb-driver/internal/logging"
 	"github.com/go-rel/sql/builder"
+	"github.com/goccy/go-json"
 )
 
 type Quote struct{}
@@ -15,7 +16,7 @@ var _ (builder.Quoter) = (*Quote)(nil)
 // IDs in SurrealDB are literally a string, same for the column.
 // No idea what the MySQL driver is doing differently here?...
 func (q Quote) ID(name string) string {
-	fmt.Println("!! QUOTER: ID", name)
+	logging.Logger().Debug("rel: quoting identifier", "name", name)
 	bytes, err := json.Marshal(name)
 	if err != nil {
 		panic(err.Error())
@@ -28,7 +29,7 @@ func (q Quote) ID(name string) string {
 // Wouldn't surprise me if there was a JSON quoter... but on the other hand,
 // I don't really need anything else. o.o
 func (q Quote) Value(v interface{}) string {
-	fmt.Println("!! QUOTER: Value", v)
+	logging.Logger().Debug("rel: quoting value", "type", fmt.Sprintf("%T", v))
 	return fmt.Sprintf("%v", v)
 	/*if s, ok := v.(interface{ String() string }); ok {
 		return s.String()
@@ -38,7 +38,7 @@ func (u Update) Build(table string, primaryField string, mutates map[string]rel.
 		case rel.ChangeSetOp:
 			buffer.WriteEscape(field)
 			buffer.WriteString(" = ")
-			buffer.WriteValue(mut.Value)
+			writeValue(&buffer, mut.Value)
 		case rel.ChangeIncOp:
 			buffer.WriteEscape(field)
 			buffer.WriteString(" += ")
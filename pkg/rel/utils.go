@@ -1,6 +1,9 @@
 package rel
 
-import "github.com/go-rel/rel"
+import (
+	"github.com/go-rel/rel"
+	"github.com/go-rel/sql/builder"
+)
 
 func deepCopyTable(table rel.Table) rel.Table {
 	// Copy primitive fields
@@ -17,3 +20,13 @@ func deepCopyTable(table rel.Table) rel.Table {
 
 	return newTable
 }
+
+// Writes NONE for st.None and empty st.Options, which removes the field
+// instead of setting it to NULL, and a placeholder for anything else.
+func writeValue(buffer *builder.Buffer, value any) {
+	if v, ok := value.(interface{ IsNone() bool }); ok && v.IsNone() {
+		buffer.WriteString("NONE")
+		return
+	}
+	buffer.WriteValue(value)
+}
@@ -2,10 +2,12 @@ package rel
 
 import (
 	"database/sql/driver"
-	"github.com/goccy/go-json"
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/IngwiePhoenix/surrealdb-driver/internal/logging"
+	"github.com/goccy/go-json"
 )
 
 var _ (driver.ValueConverter) = (*ValueConvert)(nil)
@@ -22,16 +24,16 @@ func (c ValueConvert) stringifySlice(s []string) (string, error) {
 		strs = append(strs, str.(string))
 	}
 	final := strings.Join(strs, ", ")
-	fmt.Println("!! joined: ", final)
 	return "[" + final + "]", nil
 }
 
 func (c ValueConvert) ConvertValue(v interface{}) (driver.Value, error) {
-	fmt.Printf("!! ValueConvert/rel called: %T\n", v)
-	if d, ok := v.(time.Time); ok {
+	logging.Logger().Debug("rel: converting value", "type", fmt.Sprintf("%T", v))
+	if n, ok := v.(interface{ IsNone() bool }); ok && n.IsNone() {
+		return "NONE", nil
+	} else if d, ok := v.(time.Time); ok {
 		return `d'` + d.Format(time.RFC3339) + `'`, nil
 	} else if s, ok := v.(string); ok {
-		fmt.Println("!! Left it a string.")
 		return "\"" + s + "\"", nil
 		/*str, err := json.Marshal(s)
 		if err != nil {
@@ -39,12 +41,9 @@ func (c ValueConvert) ConvertValue(v interface{}) (driver.Value, error) {
 		}
 		return string(str), nil*/
 	} else if sa, ok := v.([]string); ok {
-		fmt.Println("!! string array")
 		return c.stringifySlice(sa)
 	} else if s, ok := v.(interface{ String() string }); ok {
-		fmt.Println("!! It implements .String()string")
 		return s.String(), nil
 	}
-	fmt.Println("!! We don't give a fuck.")
 	return json.Marshal(v)
 }
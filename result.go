@@ -6,58 +6,47 @@ import (
 
 	"github.com/IngwiePhoenix/surrealdb-driver/api"
 	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
-	"github.com/clok/kemba"
 	"github.com/tidwall/gjson"
 )
 
 type SurrealResult struct {
 	RawResult *api.Response
-	k         *kemba.Kemba
-	e         *Debugger
 }
 
 var _ driver.Result = (*SurrealResult)(nil)
 
 func (r *SurrealResult) LastInsertId() (int64, error) {
-	k := r.k.Extend("LastInsertID")
 	if r.RawResult.Method != api.APIMethodQuery {
 		return 0, errors.New("can only handle query results")
 	}
 
 	var v gjson.Result
 	if r.RawResult.Result.IsArray() {
-		k.Log("result is an array")
 		a := r.RawResult.Result.Array()
 		l := len(a)
 		v = a[l-1]
 	} else {
-		k.Log("result is an object")
 		v = r.RawResult.Result
 	}
 
 	var res gjson.Result
 	var done bool = false
 	if v.IsArray() {
-		k.Log("target is an array")
 		a := v.Array()
 		l := len(a)
 		o := a[l-1]
 		if o.Get("id").Exists() {
-			k.Log("...and last element has an ID - valid.")
 			res = o
 			done = true
 		}
 	} else if v.IsObject() {
-		k.Log("target is an object")
 		if v.Get("result").Get("id").Exists() {
-			k.Log("...and has an ID, valid.")
 			res = v.Get("result")
 			done = true
 		}
 	}
 
 	if !done {
-		k.Log("Nothing found: " + r.RawResult.Method)
 		// Nothing valid was found. Yeet.
 		return 0, errors.New("could not determine ID; no record was returned")
 	}
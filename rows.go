@@ -7,7 +7,7 @@ import (
 	"sort"
 
 	"github.com/IngwiePhoenix/surrealdb-driver/api"
-	"github.com/clok/kemba"
+	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
 	"github.com/thoas/go-funk"
 	"github.com/tidwall/gjson"
 )
@@ -20,8 +20,8 @@ type SurrealRows struct {
 	realRows     []gjson.Result // Gathered results to iterate over
 	realCols     []string       // Columns per realRows
 	resultIdx    int            // Current result to be iterated over.
-	k            *kemba.Kemba   // Debug logger
-	e            *Debugger      // Error logger
+	decoding     Decoding
+	schemas      map[string]tableSchema // Field types by table, for DecodeSchema
 }
 
 var _ (driver.Rows) = (*SurrealRows)(nil)
@@ -37,18 +37,17 @@ func (rows *SurrealRows) sanityCheck() {
 }
 
 func (rows *SurrealRows) Close() error {
-	k := rows.k.Extend("Close")
-	k.Log("bye!")
-	if !rows.conn.IsValid() {
-		return driver.ErrBadConn
-	}
-	return rows.conn.Close()
+	// The whole response was read already; dropping it is all there is to
+	// do. The connection stays with database/sql.
+	rows.RawResult = nil
+	rows.realRows = nil
+	rows.realCols = nil
+	rows.resultIdx = 0
+	return nil
 }
 
 func (r *SurrealRows) Normalize() {
-	k := r.k.Extend("Normalize")
 	if r.isNormalized {
-		k.Log("early exit")
 		return
 	}
 
@@ -62,27 +61,22 @@ func (r *SurrealRows) Normalize() {
 	// Populate rows and columns
 	cols := []string{}
 	result.ForEach(func(i, value gjson.Result) bool {
-		k.Printf("Iterating through %d entry", i.Int())
 		inResult := value.Get("result")
 		if inResult.IsArray() {
 			inResult.ForEach(func(j, value gjson.Result) bool {
-				k.Printf("adding result entry: %v", j.Value())
 				entry := gjson.Parse(value.Raw)
 				r.realRows = append(r.realRows, entry)
-				k.Printf("grabbing columns: %v", j.Value())
 				entryCols := r.grabKeys(entry, entry)
 				cols = append(cols, entryCols...)
 				return true
 			})
 		} else {
-			k.Println("'twas just one result, bop it in there")
 			entry := gjson.Parse(inResult.Raw)
 			r.realRows = append(r.realRows, entry)
 		}
 		return true
 	})
 
-	k.Log("sorting cols")
 	cols = funk.UniqString(cols)
 	sort.Strings(cols)
 	r.realCols = cols
@@ -90,14 +84,10 @@ func (r *SurrealRows) Normalize() {
 }
 
 func (r *SurrealRows) grabKeys(root gjson.Result, o gjson.Result) []string {
-	k := r.k.Extend("grabKeys")
-	k.Log("<- here")
 	out := []string{}
 	o.ForEach(func(key, value gjson.Result) bool {
-		k.Printf("iterate: %s = %s", key.String(), value.String())
 		p := value.Path(root.Raw)
 		//if !value.IsObject() { // !value.IsAttay() {
-		k.Printf("appending: %s", p)
 		out = append(out, p)
 		//}
 		/*if value.IsObject() { // value.IsArray() {
@@ -115,16 +105,11 @@ func (r *SurrealRows) Columns() (cols []string) {
 	if !isQueryResponse(&r.RawResult.Result) {
 		panic("can not get columns from non-query response: " + r.RawResult.Method)
 	}
-	k := r.k.Extend("Columns")
-	k.Log("returning columns")
 	return r.realCols
 }
 
 func (r *SurrealRows) Next(dest []driver.Value) error {
-	k := r.k.Extend("Next")
-
 	if r.resultIdx >= len(r.realRows) {
-		k.Log("Reached end!")
 		return io.EOF
 	}
 
@@ -133,27 +118,38 @@ func (r *SurrealRows) Next(dest []driver.Value) error {
 	// - quix.0.name
 	currRow := r.realRows[r.resultIdx]
 	cols := r.Columns()
-	k.Log(cols)
 	for idx, path := range cols {
 		v := currRow.Get(path)
-		k.Printf("reading path '%s' into '%d' as %s", path, idx, v.Type)
 		if v.Exists() {
 			if v.IsArray() || v.IsObject() {
-				k.Printf("PUT \"%s\" dest[%d] = []byte(%s)", path, idx, v.String())
 				dest[idx] = []byte(v.Raw)
 			} else {
-				vv := v.Value()
-				k.Printf("PUT \"%s\" dest[%d] = %v", path, idx, vv)
-				vv, err := convertValue(v)
+				vv, err := r.convert(currRow, path, v)
 				if err != nil {
 					return err
 				}
 				dest[idx] = vv
 			}
+		} else {
+			// NONE; dest still holds the previous row.
+			dest[idx] = nil
 		}
 	}
 
-	k.Log("incrementing r.resultIdx")
 	r.resultIdx++
 	return nil
 }
+
+// Converts a column's value according to the decoding.
+func (r *SurrealRows) convert(row gjson.Result, path string, v gjson.Result) (driver.Value, error) {
+	switch r.decoding {
+	case DecodeHeuristic:
+		return sniffValue(v)
+	case DecodeSchema:
+		table, _ := st.TableOf(row.Get("id").String())
+		if kind := r.schemas[table][path]; kind != "" {
+			return convertField(v, kind)
+		}
+	}
+	return convertValue(v)
+}
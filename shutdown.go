@@ -0,0 +1,120 @@
+package surrealdbdriver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// How long Close() may take if nothing else was configured.
+const defaultCloseTimeout = 5 * time.Second
+
+func (c *SurrealConnector) closeTimeout() time.Duration {
+	if c.CloseTimeout > 0 {
+		return c.CloseTimeout
+	}
+	return defaultCloseTimeout
+}
+
+// implements driver.Conn
+func (con *SurrealConn) Close() error {
+	timeout := defaultCloseTimeout
+	if con.connector != nil {
+		timeout = con.connector.closeTimeout()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return con.Shutdown(ctx)
+}
+
+// Shutdown closes the connection gracefully:
+//
+//  1. New requests are refused.
+//  2. Requests in flight are waited for.
+//  3. Every live query started on this connection is killed.
+//  4. The session is invalidated.
+//  5. A WebSocket close frame is sent and the server's answer awaited.
+//
+// Once ctx is done, the remaining steps are skipped and the socket is closed
+// right away. Errors in steps 3 and 4 are not reported; the server cleans up
+// after a vanished session eventually, too.
+func (con *SurrealConn) Shutdown(ctx context.Context) error {
+	con.stateMu.Lock()
+	if con.draining {
+		con.stateMu.Unlock()
+		return nil
+	}
+	con.draining = true
+	con.stateMu.Unlock()
+
+	if con.connector != nil && con.connector.Hooks.OnClose != nil {
+		con.connector.Hooks.OnClose(con)
+	}
+
+	if !con.bad.Load() {
+		drained := make(chan struct{})
+		go func() {
+			con.inflight.Wait()
+			close(drained)
+		}()
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			con.log.Warn("gave up waiting for requests in flight")
+		}
+	}
+
+	if !con.bad.Load() && ctx.Err() == nil {
+		for _, id := range con.LiveQueries() {
+			if _, err := con.send(ctx, con.Caller.CallKill(id)); err != nil {
+				con.log.Warn("could not kill live query", "live_id", id, "error", err)
+			}
+		}
+		if _, err := con.send(ctx, con.Caller.CallInvalidate()); err != nil {
+			con.log.Warn("could not invalidate the session", "error", err)
+		}
+	}
+
+	// From here on, the reader stopping is expected.
+	con.closing.Store(true)
+	var closeErr error
+	if !con.bad.Load() && con.WSClient != nil {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			deadline = time.Now().Add(defaultCloseTimeout)
+		}
+		con.writeMu.Lock()
+		closeErr = con.WSClient.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+			deadline,
+		)
+		con.writeMu.Unlock()
+		if closeErr == nil {
+			// The server answers with a close frame of its own, which ends
+			// the reader.
+			select {
+			case <-con.done:
+			case <-ctx.Done():
+			}
+		}
+	}
+
+	con.liveMu.Lock()
+	con.countLive(-len(con.lives))
+	for id, ch := range con.lives {
+		if ch != nil {
+			close(ch)
+		}
+		delete(con.lives, id)
+	}
+	con.liveMu.Unlock()
+
+	err := con.transport.Close()
+	if closeErr != nil && !errors.Is(closeErr, websocket.ErrCloseSent) {
+		return closeErr
+	}
+	return err
+}
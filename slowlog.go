@@ -0,0 +1,158 @@
+package surrealdbdriver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/IngwiePhoenix/surrealdb-driver/api"
+	"github.com/IngwiePhoenix/surrealdb-driver/internal/logging"
+	"github.com/tidwall/gjson"
+)
+
+// DefaultRedactVars matches the names of variables whose values the slow
+// query log leaves out unless told otherwise.
+var DefaultRedactVars = regexp.MustCompile(`(?i)pass|secret|token|credential|api_?key|auth`)
+
+// SlowQueryLog configures logging of slow queries, see WithSlowQueryLog.
+type SlowQueryLog struct {
+	// Log queries that took longer than this, from sending until the
+	// response arrived. 0 disables this check.
+	Threshold time.Duration
+	// Log queries with a statement that took longer than this, according
+	// to the time SurrealDB reported. 0 disables this check.
+	StatementThreshold time.Duration
+	// Where to log to; by default the connection's logger.
+	Logger *slog.Logger
+	// The level to log at; the zero value is slog.LevelInfo.
+	Level slog.Level
+	// Variables whose name matches any of these are logged as [REDACTED].
+	// nil means DefaultRedactVars; an empty slice redacts nothing.
+	RedactVars []*regexp.Regexp
+	// Extracts attributes from the caller's context, i.e. a request or
+	// tenant ID.
+	ContextAttrs func(ctx context.Context) []slog.Attr
+}
+
+// WithSlowQueryLog logs every query that exceeds one of the thresholds,
+// with its text, its variables (redacted), namespace and database, and the
+// time each of its statements took:
+//
+//	surrealdbdriver.WithSlowQueryLog(surrealdbdriver.SlowQueryLog{
+//		Threshold:          time.Second,
+//		StatementThreshold: 200 * time.Millisecond,
+//		Level:              slog.LevelWarn,
+//	})
+//
+// It is a middleware; only the time spent in middleware added after it
+// counts towards Threshold.
+func WithSlowQueryLog(cfg SlowQueryLog) Option {
+	return func(c *SurrealConnector) error {
+		if cfg.Threshold <= 0 && cfg.StatementThreshold <= 0 {
+			return errors.New("slow query log needs a threshold")
+		}
+		if cfg.RedactVars == nil {
+			cfg.RedactVars = []*regexp.Regexp{DefaultRedactVars}
+		}
+		c.Middleware = append(c.Middleware, &slowQueryLog{cfg: cfg})
+		return nil
+	}
+}
+
+type slowQueryLog struct {
+	cfg SlowQueryLog
+}
+
+func (s *slowQueryLog) Before(ctx context.Context, req *api.Request) (context.Context, error) {
+	return ctx, nil
+}
+
+func (s *slowQueryLog) After(ctx context.Context, req *api.Request, resp *api.Response, err error, d time.Duration) {
+	if req.Method != api.APIMethodQuery {
+		return
+	}
+	slow := s.cfg.Threshold > 0 && d > s.cfg.Threshold
+
+	var statements []slog.Attr
+	if resp != nil {
+		resp.Result.ForEach(func(idx, stmt gjson.Result) bool {
+			took := stmt.Get("time").String()
+			if d, err := time.ParseDuration(took); err == nil && s.cfg.StatementThreshold > 0 && d > s.cfg.StatementThreshold {
+				slow = true
+			}
+			statements = append(statements, slog.Group(idx.String(),
+				slog.String("time", took),
+				slog.String("status", stmt.Get("status").String()),
+			))
+			return true
+		})
+	}
+	if !slow {
+		return
+	}
+
+	logger := s.cfg.Logger
+	con, ok := ConnFromContext(ctx)
+	if logger == nil {
+		if ok {
+			logger = con.log
+		} else {
+			logger = logging.Logger()
+		}
+	}
+	if !logger.Enabled(ctx, s.cfg.Level) {
+		return
+	}
+
+	params, _ := req.Params.([]any)
+	var sql string
+	var vars map[string]any
+	if len(params) > 0 {
+		sql, _ = params[0].(string)
+	}
+	if len(params) > 1 {
+		vars, _ = params[1].(map[string]interface{})
+	}
+
+	attrs := []slog.Attr{
+		slog.String("request_id", req.ID),
+		slog.Duration("duration", d),
+		slog.String("query", sql),
+		slog.Attr{Key: "vars", Value: slog.GroupValue(s.redact(vars)...)},
+		slog.Attr{Key: "statements", Value: slog.GroupValue(statements...)},
+	}
+	if ok {
+		attrs = append(attrs, slog.String("ns", con.Namespace()), slog.String("db", con.Database()))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	if s.cfg.ContextAttrs != nil {
+		attrs = append(attrs, s.cfg.ContextAttrs(ctx)...)
+	}
+	logger.LogAttrs(ctx, s.cfg.Level, "slow query", attrs...)
+}
+
+// The variables in a stable order, with secret ones redacted.
+func (s *slowQueryLog) redact(vars map[string]any) []slog.Attr {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make([]slog.Attr, 0, len(names))
+	for _, name := range names {
+		value := slog.AnyValue(vars[name])
+		for _, re := range s.cfg.RedactVars {
+			if re.MatchString(name) {
+				value = slog.StringValue(logging.Redacted)
+				break
+			}
+		}
+		out = append(out, slog.Attr{Key: name, Value: value})
+	}
+	return out
+}
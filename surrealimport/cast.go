@@ -0,0 +1,126 @@
+package surrealimport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// Cast converts a field's value. CSV values arrive as strings, JSON values as
+// json.Number, string, bool, nil, []any or map[string]any.
+type Cast func(v any) (any, error)
+
+var (
+	// String turns numbers and booleans into their text.
+	String Cast = func(v any) (any, error) {
+		switch v := v.(type) {
+		case nil, string:
+			return v, nil
+		case map[string]any, []any:
+			return nil, fmt.Errorf("can not turn %T into a string", v)
+		default:
+			return fmt.Sprint(v), nil
+		}
+	}
+	// Int parses whole numbers; "" becomes null.
+	Int Cast = func(v any) (any, error) {
+		s, ok := text(v)
+		if !ok || s == "" {
+			return nilIfEmpty(v, s, ok)
+		}
+		return strconv.ParseInt(s, 10, 64)
+	}
+	// Float parses numbers; "" becomes null.
+	Float Cast = func(v any) (any, error) {
+		s, ok := text(v)
+		if !ok || s == "" {
+			return nilIfEmpty(v, s, ok)
+		}
+		return strconv.ParseFloat(s, 64)
+	}
+	// Bool parses true/false, 1/0, yes/no; "" becomes null.
+	Bool Cast = func(v any) (any, error) {
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+		s, ok := text(v)
+		if !ok || s == "" {
+			return nilIfEmpty(v, s, ok)
+		}
+		switch strings.ToLower(s) {
+		case "true", "t", "1", "yes", "y":
+			return true, nil
+		case "false", "f", "0", "no", "n":
+			return false, nil
+		}
+		return nil, fmt.Errorf("not a boolean: %q", s)
+	}
+	// JSON parses a string holding JSON, i.e. a CSV column with arrays or
+	// objects; "" becomes null.
+	JSON Cast = func(v any) (any, error) {
+		s, ok := v.(string)
+		if !ok {
+			return v, nil
+		}
+		if s == "" {
+			return nil, nil
+		}
+		var out any
+		decoder := json.NewDecoder(strings.NewReader(s))
+		decoder.UseNumber()
+		if err := decoder.Decode(&out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	// Null turns "" into null and leaves everything else alone.
+	Null Cast = func(v any) (any, error) {
+		if v == "" {
+			return nil, nil
+		}
+		return v, nil
+	}
+)
+
+// Casts by name, as accepted by ParseCast.
+var casts = map[string]Cast{
+	"string": String,
+	"int":    Int,
+	"float":  Float,
+	"bool":   Bool,
+	"json":   JSON,
+	"null":   Null,
+}
+
+// ParseCast looks up a cast by its name: string, int, float, bool, json or
+// null.
+func ParseCast(name string) (Cast, error) {
+	cast, ok := casts[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown cast %q", name)
+	}
+	return cast, nil
+}
+
+// The text of a string or JSON number.
+func text(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v), true
+	case json.Number:
+		return v.String(), true
+	}
+	return "", false
+}
+
+func nilIfEmpty(v any, s string, ok bool) (any, error) {
+	if ok && s == "" {
+		return nil, nil
+	}
+	if v == nil {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("can not convert %T", v)
+}
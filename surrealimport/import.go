@@ -0,0 +1,329 @@
+/*
+Package surrealimport loads CSV or JSON Lines into a table, in batches:
+
+	res, err := surrealimport.Import(ctx, db, file, surrealimport.Config{
+		Table:     "person",
+		Format:    surrealimport.CSV,
+		IDField:   "username",
+		Casts:     map[string]surrealimport.Cast{"age": surrealimport.Int},
+		BatchSize: 500,
+	})
+
+The input is streamed; only one batch is held in memory. Batches are written
+through the `insert` RPC, or as `INSERT INTO table $records` with UseQuery.
+
+If a batch fails, Import stops and Result.Offset tells how many records were
+dealt with before it; passing that as Config.Offset resumes the import. With
+ContinueOnError, failed batches are collected in Result.Errors instead.
+
+The surrealimport command wraps this for the shell.
+*/
+package surrealimport
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	surrealdbdriver "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/goccy/go-json"
+)
+
+// Format is the kind of input.
+type Format int
+
+const (
+	JSONL Format = iota // One JSON object per line
+	CSV                 // Comma separated, with a header row
+)
+
+// ParseFormat looks up a format by name: csv, jsonl (or ndjson).
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "csv":
+		return CSV, nil
+	case "jsonl", "ndjson":
+		return JSONL, nil
+	}
+	return 0, fmt.Errorf("unknown format %q", name)
+}
+
+// DefaultBatchSize is used when Config.BatchSize is not set.
+const DefaultBatchSize = 1000
+
+// Config describes an import.
+type Config struct {
+	Table  string // Required
+	Format Format
+
+	// For CSV without a header row, the column names. Otherwise, the first
+	// row names the columns.
+	Header []string
+	// CSV field delimiter; ',' if not set.
+	Comma rune
+
+	// The field (after renaming) holding the record ID. It is moved to
+	// `id`; without it, SurrealDB generates IDs.
+	IDField string
+	// Renames fields: source name to field name. Fields not listed keep
+	// their name, unless OnlyRenamed is set.
+	Rename      map[string]string
+	OnlyRenamed bool
+	// Converts fields (by name after renaming) before they are written.
+	// CSV values are strings to begin with, so this is how they become
+	// numbers, booleans and so on.
+	Casts map[string]Cast
+
+	BatchSize int
+	// Skip this many records first; see Result.Offset.
+	Offset int64
+	// Use `INSERT INTO <table> $records` instead of the `insert` RPC.
+	UseQuery bool
+	// Keep going when a batch fails.
+	ContinueOnError bool
+	// Called after every batch, failed or not.
+	OnProgress func(Progress)
+}
+
+// Progress is reported after every batch.
+type Progress struct {
+	Batch    int   // Number of the batch, starting at 1
+	Offset   int64 // Records dealt with so far, including skipped ones
+	Imported int64 // Records written so far
+	Failed   int64 // Records in failed batches so far
+	Err      error // Why this batch failed, if it did
+}
+
+// Result sums up an import.
+type Result struct {
+	Offset   int64 // Records dealt with; resume from here
+	Imported int64
+	Failed   int64
+	Errors   []*BatchError // With ContinueOnError
+}
+
+// BatchError is a batch that could not be written.
+type BatchError struct {
+	Offset int64 // Of the batch's first record
+	Count  int
+	Err    error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("records %d to %d: %s", e.Offset, e.Offset+int64(e.Count)-1, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Import reads records from r and writes them to the database, using a
+// single connection of db throughout.
+func Import(ctx context.Context, db *sql.DB, r io.Reader, cfg Config) (*Result, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var res *Result
+	err = conn.Raw(func(driverConn any) error {
+		con, ok := driverConn.(*surrealdbdriver.SurrealConn)
+		if !ok {
+			return fmt.Errorf("not a SurrealDB connection: %T", driverConn)
+		}
+		res, err = ImportConn(ctx, con, r, cfg)
+		return err
+	})
+	return res, err
+}
+
+// ImportConn is Import on a connection of its own.
+func ImportConn(ctx context.Context, con *surrealdbdriver.SurrealConn, r io.Reader, cfg Config) (*Result, error) {
+	if cfg.Table == "" {
+		return nil, errors.New("no table given")
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	var next func() (map[string]any, error)
+	switch cfg.Format {
+	case CSV:
+		next = csvRecords(r, cfg)
+	case JSONL:
+		next = jsonlRecords(r)
+	default:
+		return nil, fmt.Errorf("unknown format %d", cfg.Format)
+	}
+
+	res := &Result{}
+	batch := make([]map[string]any, 0, cfg.BatchSize)
+	batches := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		batches++
+		err := write(ctx, con, cfg, batch)
+		p := Progress{Batch: batches}
+		if err != nil {
+			berr := &BatchError{Offset: res.Offset, Count: len(batch), Err: err}
+			p.Err = berr
+			if !cfg.ContinueOnError {
+				if cfg.OnProgress != nil {
+					p.Offset, p.Imported, p.Failed = res.Offset, res.Imported, res.Failed+int64(len(batch))
+					cfg.OnProgress(p)
+				}
+				return berr
+			}
+			res.Errors = append(res.Errors, berr)
+			res.Failed += int64(len(batch))
+		} else {
+			res.Imported += int64(len(batch))
+		}
+		res.Offset += int64(len(batch))
+		batch = batch[:0]
+		if cfg.OnProgress != nil {
+			p.Offset, p.Imported, p.Failed = res.Offset, res.Imported, res.Failed
+			cfg.OnProgress(p)
+		}
+		return nil
+	}
+
+	for idx := int64(0); ; idx++ {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		record, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return res, fmt.Errorf("record %d: %w", idx, err)
+		}
+		if idx < cfg.Offset {
+			res.Offset++
+			continue
+		}
+		if record, err = shape(record, cfg); err != nil {
+			return res, fmt.Errorf("record %d: %w", idx, err)
+		}
+		batch = append(batch, record)
+		if len(batch) == cfg.BatchSize {
+			if err := flush(); err != nil {
+				return res, err
+			}
+		}
+	}
+	return res, flush()
+}
+
+// Renames, casts and picks out the ID.
+func shape(in map[string]any, cfg Config) (map[string]any, error) {
+	out := make(map[string]any, len(in))
+	for name, v := range in {
+		if to, ok := cfg.Rename[name]; ok {
+			name = to
+		} else if cfg.OnlyRenamed {
+			continue
+		}
+		if cast, ok := cfg.Casts[name]; ok {
+			var err error
+			if v, err = cast(v); err != nil {
+				return nil, fmt.Errorf("field %s: %w", name, err)
+			}
+		}
+		out[name] = v
+	}
+	if cfg.IDField != "" {
+		id, ok := out[cfg.IDField]
+		if !ok || id == nil || id == "" {
+			return nil, fmt.Errorf("no value for the ID field %s", cfg.IDField)
+		}
+		delete(out, cfg.IDField)
+		out["id"] = id
+	}
+	return out, nil
+}
+
+func write(ctx context.Context, con *surrealdbdriver.SurrealConn, cfg Config, batch []map[string]any) error {
+	if !cfg.UseQuery {
+		_, err := con.Insert(ctx, cfg.Table, batch)
+		return err
+	}
+	b := &surrealdbdriver.Batch{}
+	b.Queue("INSERT INTO "+escapeIdent(cfg.Table)+" $records", map[string]any{"records": batch})
+	results, err := con.SendBatch(ctx, b)
+	if err != nil {
+		return err
+	}
+	return results.Close()
+}
+
+// Table names that are not plain identifiers need to be escaped.
+func escapeIdent(name string) string {
+	for _, c := range name {
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return "⟨" + strings.ReplaceAll(name, "⟩", `\⟩`) + "⟩"
+		}
+	}
+	return name
+}
+
+func csvRecords(r io.Reader, cfg Config) func() (map[string]any, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	if cfg.Comma != 0 {
+		reader.Comma = cfg.Comma
+	}
+	header := cfg.Header
+	return func() (map[string]any, error) {
+		if header == nil {
+			row, err := reader.Read()
+			if err != nil {
+				return nil, err
+			}
+			header = append([]string(nil), row...)
+		}
+		row, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		if len(row) != len(header) {
+			return nil, fmt.Errorf("%d fields, but %d columns", len(row), len(header))
+		}
+		out := make(map[string]any, len(row))
+		for i, v := range row {
+			out[header[i]] = v
+		}
+		return out, nil
+	}
+}
+
+func jsonlRecords(r io.Reader) func() (map[string]any, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	return func() (map[string]any, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			decoder := json.NewDecoder(strings.NewReader(line))
+			decoder.UseNumber()
+			var out map[string]any
+			if err := decoder.Decode(&out); err != nil {
+				return nil, err
+			}
+			return out, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+}
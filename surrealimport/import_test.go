@@ -0,0 +1,114 @@
+package surrealimport_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	_ "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealimport"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealtest"
+)
+
+const people = `user,age,active,tags
+tobie,35,yes,"[""founder""]"
+jaime,34,no,[]
+bad,x,no,[]
+ana,,yes,[]
+`
+
+func TestImport(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	var batches [][]any
+	srv.On("insert").Handle(func(req surrealtest.Request) (any, error) {
+		if table := req.Params.Get("0").String(); table != "person" {
+			t.Errorf("table %q", table)
+		}
+		records := req.Params.Get("1").Value().([]any)
+		batches = append(batches, records)
+		if len(batches) == 2 {
+			return nil, &surrealtest.Error{Code: -32000, Message: "There was a problem with the database"}
+		}
+		return records, nil
+	})
+	db, err := sql.Open("surrealdb", srv.DSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cfg := surrealimport.Config{
+		Table:     "person",
+		Format:    surrealimport.CSV,
+		IDField:   "username",
+		Rename:    map[string]string{"user": "username"},
+		Casts:     map[string]surrealimport.Cast{"age": surrealimport.Int, "active": surrealimport.Bool, "tags": surrealimport.JSON},
+		BatchSize: 2,
+	}
+
+	t.Run("Stops at a failed batch", func(t *testing.T) {
+		_, err := surrealimport.Import(context.Background(), db, strings.NewReader(people), cfg)
+		if err == nil || !strings.Contains(err.Error(), `record 2: field age`) {
+			t.Fatalf("got %v", err)
+		}
+		want := `[map[active:true age:35 id:tobie tags:[founder]] map[active:false age:34 id:jaime tags:[]]]`
+		if len(batches) != 1 || fmtAny(batches[0]) != want {
+			t.Errorf("sent %v", batches)
+		}
+	})
+
+	t.Run("Resumes", func(t *testing.T) {
+		cfg := cfg
+		cfg.Casts = map[string]surrealimport.Cast{"age": surrealimport.Null}
+		cfg.Offset = 2
+		var progress []surrealimport.Progress
+		cfg.OnProgress = func(p surrealimport.Progress) { progress = append(progress, p) }
+		res, err := surrealimport.Import(context.Background(), db, strings.NewReader(people), cfg)
+		var berr *surrealimport.BatchError
+		if !errors.As(err, &berr) || berr.Offset != 2 || berr.Count != 2 {
+			t.Fatalf("got %v", err)
+		}
+		if res.Offset != 2 || res.Imported != 0 || len(progress) != 1 || progress[0].Err == nil {
+			t.Errorf("result %+v, progress %+v", res, progress)
+		}
+
+		cfg.ContinueOnError = true
+		res, err = surrealimport.Import(context.Background(), db, strings.NewReader(people), cfg)
+		if err != nil || res.Offset != 4 || res.Imported != 2 || res.Failed != 0 {
+			t.Errorf("result %+v, %v", res, err)
+		}
+		if got := fmtAny(batches[len(batches)-1]); got != `[map[active:no age:x id:bad tags:[]] map[active:yes age:<nil> id:ana tags:[]]]` {
+			t.Errorf("sent %s", got)
+		}
+	})
+}
+
+func TestImportJSONL(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	srv.OnQueryMatch(`^INSERT INTO ⟨my-table⟩ \$_batch0_records\s*;`).Return([]any{})
+	db, err := sql.Open("surrealdb", srv.DSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	in := "{\"id\": 1, \"big\": 12345678901234567890}\n\n{\"id\": 2}\n"
+	res, err := surrealimport.Import(context.Background(), db, strings.NewReader(in), surrealimport.Config{
+		Table:    "my-table",
+		UseQuery: true,
+	})
+	if err != nil || res.Imported != 2 {
+		t.Fatalf("result %+v, %v", res, err)
+	}
+	// Numbers are passed on as they were written.
+	if big := srv.Sent("query")[0].Params.Get("1._batch0_records.0.big").Raw; big != "12345678901234567890" {
+		t.Errorf("big = %s", big)
+	}
+}
+
+func fmtAny(v any) string {
+	return fmt.Sprint(v)
+}
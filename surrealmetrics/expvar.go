@@ -0,0 +1,211 @@
+/*
+Package surrealmetrics exports the driver's measurements via expvar.
+
+	connector, err := surrealdbdriver.NewConnector(
+		surrealdbdriver.WithDSN(dsn),
+		surrealdbdriver.WithMetrics(surrealmetrics.NewExpvar("surrealdb")),
+	)
+
+The variables show up below the given name on /debug/vars. For Prometheus,
+see the surrealprom package.
+*/
+package surrealmetrics
+
+import (
+	"expvar"
+	"strconv"
+	"sync"
+	"time"
+
+	surrealdbdriver "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/IngwiePhoenix/surrealdb-driver/api"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the duration
+// histograms. They are the same as Prometheus' default buckets.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Expvar is a surrealdbdriver.Metrics that publishes to expvar:
+//
+//	requests          by method
+//	request_errors    by error class
+//	request_seconds   histogram by method
+//	statements        by error class ("ok" for successful ones)
+//	statement_seconds histogram by error class
+//	inflight          requests awaiting their response
+//	live_queries      running live queries
+//	connects          by error class ("ok" for successful ones)
+//	reconnects        connections that replaced one that went bad
+//	bad_connections   connections that turned out to be unusable
+//	bytes_sent, bytes_received
+type Expvar struct {
+	vars             *expvar.Map
+	requests         *expvar.Map
+	requestErrors    *expvar.Map
+	requestSeconds   *histograms
+	statements       *expvar.Map
+	statementSeconds *histograms
+	inflight         *expvar.Int
+	live             *expvar.Int
+	connects         *expvar.Map
+	reconnects       *expvar.Int
+	bad              *expvar.Int
+	sent, received   *expvar.Int
+}
+
+var _ surrealdbdriver.Metrics = (*Expvar)(nil)
+
+// NewExpvar publishes the variables as a map called name. Like
+// expvar.Publish, it panics if that name is taken already.
+func NewExpvar(name string) *Expvar {
+	e := newExpvar()
+	expvar.Publish(name, e.vars)
+	return e
+}
+
+// NewUnpublishedExpvar is NewExpvar without publishing; use Map to get at
+// the variables.
+func NewUnpublishedExpvar() *Expvar {
+	return newExpvar()
+}
+
+func newExpvar() *Expvar {
+	e := &Expvar{
+		vars:             new(expvar.Map),
+		requests:         new(expvar.Map),
+		requestErrors:    new(expvar.Map),
+		requestSeconds:   newHistograms(DefaultBuckets),
+		statements:       new(expvar.Map),
+		statementSeconds: newHistograms(DefaultBuckets),
+		inflight:         new(expvar.Int),
+		live:             new(expvar.Int),
+		connects:         new(expvar.Map),
+		reconnects:       new(expvar.Int),
+		bad:              new(expvar.Int),
+		sent:             new(expvar.Int),
+		received:         new(expvar.Int),
+	}
+	e.vars.Set("requests", e.requests)
+	e.vars.Set("request_errors", e.requestErrors)
+	e.vars.Set("request_seconds", e.requestSeconds)
+	e.vars.Set("statements", e.statements)
+	e.vars.Set("statement_seconds", e.statementSeconds)
+	e.vars.Set("inflight", e.inflight)
+	e.vars.Set("live_queries", e.live)
+	e.vars.Set("connects", e.connects)
+	e.vars.Set("reconnects", e.reconnects)
+	e.vars.Set("bad_connections", e.bad)
+	e.vars.Set("bytes_sent", e.sent)
+	e.vars.Set("bytes_received", e.received)
+	return e
+}
+
+// Map returns the variables.
+func (e *Expvar) Map() *expvar.Map {
+	return e.vars
+}
+
+func (e *Expvar) RequestStarted(method api.APIMethod) {
+	e.inflight.Add(1)
+}
+
+func (e *Expvar) RequestDone(method api.APIMethod, d time.Duration, class api.ErrorClass) {
+	e.inflight.Add(-1)
+	e.requests.Add(string(method), 1)
+	if class != api.ErrorClassNone {
+		e.requestErrors.Add(string(class), 1)
+	}
+	e.requestSeconds.observe(string(method), d)
+}
+
+func (e *Expvar) StatementDone(d time.Duration, class api.ErrorClass) {
+	e.statements.Add(classLabel(class), 1)
+	e.statementSeconds.observe(classLabel(class), d)
+}
+
+func (e *Expvar) Connected(class api.ErrorClass, reconnect bool) {
+	e.connects.Add(classLabel(class), 1)
+	if reconnect {
+		e.reconnects.Add(1)
+	}
+}
+
+func (e *Expvar) ConnectionBad() {
+	e.bad.Add(1)
+}
+
+func (e *Expvar) LiveQueries(delta int) {
+	e.live.Add(int64(delta))
+}
+
+func (e *Expvar) Transferred(sent, received int) {
+	e.sent.Add(int64(sent))
+	e.received.Add(int64(received))
+}
+
+func classLabel(class api.ErrorClass) string {
+	if class == api.ErrorClassNone {
+		return "ok"
+	}
+	return string(class)
+}
+
+// Cumulative histograms keyed by a label, rendered as
+// {"label": {"count": n, "sum": seconds, "le": {"0.005": n, ...}}}.
+type histograms struct {
+	buckets []float64
+	mu      sync.Mutex
+	byLabel map[string]*histogram
+}
+
+type histogram struct {
+	counts []int64 // Per bucket, plus one for +Inf
+	sum    float64
+}
+
+func newHistograms(buckets []float64) *histograms {
+	return &histograms{buckets: buckets, byLabel: map[string]*histogram{}}
+}
+
+func (h *histograms) observe(label string, d time.Duration) {
+	secs := d.Seconds()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hist, ok := h.byLabel[label]
+	if !ok {
+		hist = &histogram{counts: make([]int64, len(h.buckets)+1)}
+		h.byLabel[label] = hist
+	}
+	i := 0
+	for i < len(h.buckets) && secs > h.buckets[i] {
+		i++
+	}
+	hist.counts[i]++
+	hist.sum += secs
+}
+
+func (h *histograms) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := expvar.Map{}
+	for label, hist := range h.byLabel {
+		le := expvar.Map{}
+		var cumulative int64
+		for i, bound := range h.buckets {
+			cumulative += hist.counts[i]
+			n := new(expvar.Int)
+			n.Set(cumulative)
+			le.Set(strconv.FormatFloat(bound, 'g', -1, 64), n)
+		}
+		cumulative += hist.counts[len(h.buckets)]
+		count, sum := new(expvar.Int), new(expvar.Float)
+		count.Set(cumulative)
+		sum.Set(hist.sum)
+		one := expvar.Map{}
+		one.Set("count", count)
+		one.Set("sum", sum)
+		one.Set("le", &le)
+		out.Set(label, &one)
+	}
+	return out.String()
+}
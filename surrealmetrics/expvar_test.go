@@ -0,0 +1,84 @@
+package surrealmetrics_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	surrealdbdriver "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealmetrics"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealtest"
+)
+
+func TestExpvar(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	metrics := surrealmetrics.NewUnpublishedExpvar()
+	connector, err := surrealdbdriver.NewConnector(
+		surrealdbdriver.WithDSN(srv.DSN()),
+		surrealdbdriver.WithMetrics(metrics),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = conn.Raw(func(driverConn any) error {
+		_, _, err := driverConn.(*surrealdbdriver.SurrealConn).Live(ctx, "person", false)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if v := get(t, metrics, "live_queries"); v != float64(1) {
+		t.Errorf("live_queries = %v", v)
+	}
+
+	// The connection breaks; the next one replaces it.
+	srv.CloseConnections()
+	deadline := time.Now().Add(time.Second)
+	for get(t, metrics, "bad_connections") != float64(1) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]any{
+		"reconnects":   float64(1),
+		"inflight":     float64(0),
+		"live_queries": float64(0),
+		"connects":     map[string]any{"ok": float64(2)},
+	} {
+		got, _ := json.Marshal(get(t, metrics, name))
+		wanted, _ := json.Marshal(want)
+		if string(got) != string(wanted) {
+			t.Errorf("%s = %s, want %s", name, got, wanted)
+		}
+	}
+	if get(t, metrics, "bytes_sent") == float64(0) || get(t, metrics, "bytes_received") == float64(0) {
+		t.Error("no bytes counted")
+	}
+	seconds := get(t, metrics, "request_seconds").(map[string]any)
+	if live := seconds["live"].(map[string]any); live["count"] != float64(1) {
+		t.Errorf("request_seconds[live] = %v", live)
+	}
+}
+
+func get(t *testing.T, e *surrealmetrics.Expvar, name string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(e.Map().Get(name).String()), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
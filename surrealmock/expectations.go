@@ -0,0 +1,242 @@
+package surrealmock
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IngwiePhoenix/surrealdb-driver/api"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealtest"
+	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
+	"github.com/goccy/go-json"
+	"github.com/tidwall/gjson"
+)
+
+// Argument matches a query variable or RPC parameter. Values that are not an
+// Argument are compared by their JSON representation, as the driver sends
+// them.
+type Argument interface {
+	Match(v any) bool
+}
+
+// ArgFunc turns a function into an Argument.
+type ArgFunc func(v any) bool
+
+func (f ArgFunc) Match(v any) bool {
+	return f(v)
+}
+
+// AnyArg matches every value.
+func AnyArg() Argument {
+	return ArgFunc(func(any) bool { return true })
+}
+
+// Expectation is one request the code under test is expected to send.
+type Expectation struct {
+	method     string
+	text       string
+	pattern    *regexp.Regexp
+	args       []any
+	vars       map[string]any
+	params     []any
+	statements []surrealtest.Statement
+	result     any
+	err        error
+	delay      time.Duration
+	triggered  bool
+}
+
+// ExpectQuery expects a query with exactly this SurrealQL. Whitespace is
+// collapsed before comparing.
+//
+// The SurrealQL is compared as the driver sends it: parameters with a
+// SurrealDB type of their own are replaced by the expression restoring the
+// type, so a query using a time.Time as $_1 is expected with (<datetime>$_1),
+// a duration with (<duration>$_1) and a record ID with
+// type::thing($_1.tb, $_1.id). WithArgs takes the Go values as passed.
+func (m *Mock) ExpectQuery(sql string) *Expectation {
+	return m.add(&Expectation{method: "query", text: normalize(sql)})
+}
+
+// ExpectQueryMatch expects a query matching a regular expression. It panics
+// if the expression is invalid.
+func (m *Mock) ExpectQueryMatch(pattern string) *Expectation {
+	return m.add(&Expectation{method: "query", pattern: regexp.MustCompile(pattern)})
+}
+
+// ExpectCall expects an RPC other than `query`, i.e. "select" or "insert".
+func (m *Mock) ExpectCall(method api.APIMethod) *Expectation {
+	return m.add(&Expectation{method: string(method)})
+}
+
+// WithArgs expects positional arguments, as database/sql passes them: the
+// first one is $_1, the second one $_2 and so on.
+func (e *Expectation) WithArgs(args ...any) *Expectation {
+	e.args = args
+	return e
+}
+
+// WithVars expects named arguments, keyed by the names given to sql.Named:
+// "name" is the variable $_name.
+func (e *Expectation) WithVars(vars map[string]any) *Expectation {
+	e.vars = make(map[string]any, len(vars))
+	for name, v := range vars {
+		e.vars["_"+name] = v
+	}
+	return e
+}
+
+// WithParams expects the parameters of an RPC made with ExpectCall.
+func (e *Expectation) WithParams(params ...any) *Expectation {
+	e.params = params
+	return e
+}
+
+// WillReturn sets the result. For queries, every value is the result of one
+// statement, so WillReturn(a, b) answers a query of two statements. For other
+// RPCs, a single value is returned as is and several as an array.
+func (e *Expectation) WillReturn(results ...any) *Expectation {
+	if e.method == "query" {
+		for _, result := range results {
+			e.statements = append(e.statements, surrealtest.OK(result))
+		}
+		return e
+	}
+	if len(results) == 1 {
+		e.result = results[0]
+	} else {
+		e.result = results
+	}
+	return e
+}
+
+// WillReturnStatements appends arbitrary statements to a query's response,
+// i.e. surrealtest.ERR for a failing one.
+func (e *Expectation) WillReturnStatements(statements ...surrealtest.Statement) *Expectation {
+	e.statements = append(e.statements, statements...)
+	return e
+}
+
+// WillFailStatement appends a failing statement to a query's response.
+func (e *Expectation) WillFailStatement(message string) *Expectation {
+	return e.WillReturnStatements(surrealtest.ERR(message))
+}
+
+// WillReturnError answers with an RPC error. An *api.APIError keeps its code.
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// WillDelayFor holds the answer back, i.e. to test timeouts.
+func (e *Expectation) WillDelayFor(d time.Duration) *Expectation {
+	e.delay = d
+	return e
+}
+
+func (e *Expectation) String() string {
+	out := &strings.Builder{}
+	out.WriteString(e.method)
+	switch {
+	case e.pattern != nil:
+		out.WriteString(" matching " + strconv.Quote(e.pattern.String()))
+	case e.text != "":
+		out.WriteString(" " + strconv.Quote(e.text))
+	}
+	if len(e.args) > 0 {
+		fmt.Fprintf(out, " with args %v", e.args)
+	}
+	if len(e.vars) > 0 {
+		fmt.Fprintf(out, " with vars %v", e.vars)
+	}
+	if len(e.params) > 0 {
+		fmt.Fprintf(out, " with params %v", e.params)
+	}
+	return out.String()
+}
+
+func (e *Expectation) matches(method string, params gjson.Result) bool {
+	if e.method != method {
+		return false
+	}
+	if method != "query" {
+		if e.params == nil {
+			return true
+		}
+		actual := params.Array()
+		if len(actual) != len(e.params) {
+			return false
+		}
+		for i, p := range e.params {
+			if !matchValue(p, actual[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	sql := params.Get("0").String()
+	if e.pattern != nil && !e.pattern.MatchString(sql) {
+		return false
+	}
+	if e.pattern == nil && e.text != "" && e.text != normalize(sql) {
+		return false
+	}
+	vars := params.Get("1")
+	for i, arg := range e.args {
+		if !matchValue(arg, vars.Get(gjson.Escape("_"+strconv.Itoa(i+1)))) {
+			return false
+		}
+	}
+	for name, v := range e.vars {
+		if !matchValue(v, vars.Get(gjson.Escape(name))) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchValue(expected any, actual gjson.Result) bool {
+	var got any
+	if actual.Exists() {
+		if err := json.Unmarshal([]byte(actual.Raw), &got); err != nil {
+			return false
+		}
+	}
+	if arg, ok := expected.(Argument); ok {
+		return arg.Match(got)
+	}
+	if !actual.Exists() {
+		return false
+	}
+	// Sent the way the driver binds it, i.e. a time.Time in UTC.
+	_, expected = st.BindParam(expected)
+	raw, err := json.Marshal(expected)
+	if err != nil {
+		return false
+	}
+	var want any
+	if err := json.Unmarshal(raw, &want); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(want, got)
+}
+
+func (e *Expectation) answer() (any, error) {
+	if e.delay > 0 {
+		time.Sleep(e.delay)
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	if e.method == "query" {
+		return e.statements, nil
+	}
+	if e.method == string(api.APIMethodLive) && e.result == nil {
+		return newLiveID(), nil
+	}
+	return e.result, nil
+}
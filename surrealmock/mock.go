@@ -0,0 +1,297 @@
+/*
+Package surrealmock is a mock database/sql driver, in the spirit of sqlmock,
+for asserting which SurrealQL an application runs without running anything.
+
+	db, mock, err := surrealmock.New()
+	mock.ExpectQuery("SELECT * FROM user WHERE age > $_1").
+		WithArgs(18).
+		WillReturn([]map[string]any{{"id": "user:tobie", "age": 42}})
+
+	// ... code under test using db ...
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+Behind the scenes, the real driver connection is used with a fake transport,
+so rows and results are shaped exactly like they would be against SurrealDB.
+The driver is registered as "surrealmock"; the *sql.DB works for pkg/rel and
+pkg/gorm just as well.
+
+signin, authenticate, use, let, unset, kill, invalidate and version are answered
+automatically; everything else needs an expectation.
+*/
+package surrealmock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	surrealdbdriver "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/IngwiePhoenix/surrealdb-driver/api"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealtest"
+	"github.com/goccy/go-json"
+	"github.com/gofrs/uuid/v5"
+	"github.com/tidwall/gjson"
+)
+
+// The name the mock driver is registered under.
+const DriverName = "surrealmock"
+
+var (
+	mocksMu sync.Mutex
+	mocks   = map[string]*Mock{}
+	mockSeq atomic.Uint64
+)
+
+func init() {
+	sql.Register(DriverName, &mockDriver{})
+}
+
+// Option configures a Mock.
+type Option func(*Mock)
+
+// Unordered lets expectations be met in any order. By default, they have to
+// be met in the order they were declared.
+func Unordered() Option {
+	return func(m *Mock) {
+		m.ordered = false
+	}
+}
+
+// Mock holds the expectations for one *sql.DB.
+type Mock struct {
+	dsn       string
+	connector *surrealdbdriver.SurrealConnector
+	ordered   bool
+
+	mu         sync.Mutex
+	expected   []*Expectation
+	unexpected []string
+}
+
+// New creates a mock and a *sql.DB using it. Closing the DB unregisters the
+// mock; its DSN stops working then.
+func New(opts ...Option) (*sql.DB, *Mock, error) {
+	m := &Mock{
+		dsn:     DriverName + "_" + strconv.FormatUint(mockSeq.Add(1), 10),
+		ordered: true,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	connector, err := surrealdbdriver.NewConnector(
+		surrealdbdriver.WithEndpoint("ws://"+m.dsn+"/rpc"),
+		surrealdbdriver.WithTransport(m.dial),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	m.connector = connector
+
+	mocksMu.Lock()
+	mocks[m.dsn] = m
+	mocksMu.Unlock()
+	return sql.OpenDB(mockConnector{SurrealConnector: connector, mock: m}), m, nil
+}
+
+// The connector of the *sql.DB New returns. Closing the DB forgets the
+// mock, so that a long test binary does not keep every mock alive.
+type mockConnector struct {
+	*surrealdbdriver.SurrealConnector
+	mock *Mock
+}
+
+func (c mockConnector) Close() error {
+	mocksMu.Lock()
+	delete(mocks, c.mock.dsn)
+	mocksMu.Unlock()
+	return nil
+}
+
+// DSN is the name the mock is known under to the "surrealmock" driver, i.e.
+// for sql.Open(surrealmock.DriverName, mock.DSN()).
+func (m *Mock) DSN() string {
+	return m.dsn
+}
+
+// ExpectationsWereMet reports every expectation that was not met and every
+// request that was not expected.
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var errs []error
+	for _, e := range m.expected {
+		if !e.triggered {
+			errs = append(errs, errors.New("unmet expectation: "+e.String()))
+		}
+	}
+	for _, u := range m.unexpected {
+		errs = append(errs, errors.New("unexpected request: "+u))
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Mock) add(e *Expectation) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expected = append(m.expected, e)
+	return e
+}
+
+// Finds the expectation for a request and marks it as met.
+func (m *Mock) find(method string, params gjson.Result) (*Expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.expected {
+		if e.triggered {
+			continue
+		}
+		if e.matches(method, params) {
+			e.triggered = true
+			return e, nil
+		}
+		if m.ordered {
+			err := fmt.Errorf("surrealmock: %s was not expected, next expectation is %s", describe(method, params), e)
+			m.unexpected = append(m.unexpected, describe(method, params))
+			return nil, err
+		}
+	}
+	m.unexpected = append(m.unexpected, describe(method, params))
+	return nil, fmt.Errorf("surrealmock: %s was not expected", describe(method, params))
+}
+
+// Finds who answers a request: either one of the automatic answers or an
+// expectation.
+func (m *Mock) answerer(method string, params gjson.Result) (func() (any, error), error) {
+	switch method {
+	case "signin", "signup":
+		return func() (any, error) { return "surrealmock-token", nil }, nil
+	case "authenticate", "use", "let", "unset", "kill", "invalidate":
+		return func() (any, error) { return nil, nil }, nil
+	case "version":
+		return func() (any, error) { return surrealtest.Version, nil }, nil
+	}
+	e, err := m.find(method, params)
+	if err != nil {
+		return nil, err
+	}
+	return e.answer, nil
+}
+
+func (m *Mock) dial(ctx context.Context) (surrealdbdriver.Transport, error) {
+	return &transport{
+		mock:   m,
+		out:    make(chan []byte, 16),
+		closed: make(chan struct{}),
+	}, nil
+}
+
+type mockDriver struct{}
+
+var _ driver.DriverContext = (*mockDriver)(nil)
+
+func (d *mockDriver) Open(dsn string) (driver.Conn, error) {
+	connector, err := d.OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(context.Background())
+}
+
+func (d *mockDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	mocksMu.Lock()
+	m, ok := mocks[dsn]
+	mocksMu.Unlock()
+	if !ok {
+		return nil, errors.New("surrealmock: no mock named " + dsn)
+	}
+	return m.connector, nil
+}
+
+// Hands requests to the mock and queues its answers for the reader.
+type transport struct {
+	mock      *Mock
+	out       chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (t *transport) WriteMessage(msg []byte) error {
+	req := gjson.ParseBytes(msg)
+	id := req.Get("id").String()
+	// Expectations are looked up right away, so that they are met in the
+	// order the requests were sent, even if answering takes a while.
+	answer, err := t.mock.answerer(req.Get("method").String(), req.Get("params"))
+	if err != nil {
+		return t.respond(id, nil, err)
+	}
+	go func() {
+		result, err := answer()
+		t.respond(id, result, err)
+	}()
+	return nil
+}
+
+func (t *transport) respond(id string, result any, err error) error {
+	resp := map[string]any{"id": id}
+	if err != nil {
+		apiErr := &api.APIError{Code: -32000, Message: err.Error()}
+		errors.As(err, &apiErr)
+		resp["error"] = apiErr
+	} else {
+		resp["result"] = result
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	select {
+	case t.out <- data:
+		return nil
+	case <-t.closed:
+		return errors.New("surrealmock: connection closed")
+	}
+}
+
+func (t *transport) ReadMessage() ([]byte, error) {
+	select {
+	case msg := <-t.out:
+		return msg, nil
+	case <-t.closed:
+		return nil, errors.New("surrealmock: connection closed")
+	}
+}
+
+func (t *transport) Close() error {
+	t.closeOnce.Do(func() { close(t.closed) })
+	return nil
+}
+
+func describe(method string, params gjson.Result) string {
+	if method == "query" {
+		out := "query " + strconv.Quote(params.Get("0").String())
+		if vars := params.Get("1"); vars.IsObject() {
+			out += " with " + vars.Raw
+		}
+		return out
+	}
+	return method + " " + params.Raw
+}
+
+// Collapses whitespace so that queries can be compared regardless of
+// indentation.
+func normalize(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+func newLiveID() string {
+	return uuid.Must(uuid.NewV4()).String()
+}
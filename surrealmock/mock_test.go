@@ -0,0 +1,223 @@
+package surrealmock_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IngwiePhoenix/surrealdb-driver/api"
+	sgorm "github.com/IngwiePhoenix/surrealdb-driver/pkg/gorm"
+	srel "github.com/IngwiePhoenix/surrealdb-driver/pkg/rel"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealmock"
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+	"gorm.io/gorm"
+)
+
+type User struct {
+	ID   string
+	Name string
+	Age  int
+}
+
+func TestMock(t *testing.T) {
+	t.Run("Query", func(t *testing.T) {
+		db, mock, err := surrealmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		mock.ExpectQuery("SELECT name FROM user WHERE age > $_1").
+			WithArgs(18).
+			WillReturn([]map[string]any{{"name": "Tobie"}, {"name": "Jaime"}})
+
+		rows, err := db.Query("SELECT name FROM user WHERE age > $_1", 18)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, name)
+		}
+		rows.Close()
+		if strings.Join(names, ",") != "Tobie,Jaime" {
+			t.Errorf("got %v", names)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("TypedArgs", func(t *testing.T) {
+		db, mock, err := surrealmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		at := time.Date(2024, 5, 6, 7, 8, 9, 0, time.FixedZone("", 3600))
+		mock.ExpectQuery("CREATE event SET at = (<datetime>$_1), took = (<duration>$_2)").
+			WithArgs(at, 90*time.Minute).
+			WillReturn([]any{})
+
+		if _, err := db.Exec("CREATE event SET at = $_1, took = $_2", at, 90*time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("NamedVars", func(t *testing.T) {
+		db, mock, err := surrealmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		mock.ExpectQuery("SELECT * FROM user WHERE name = $_name").
+			WithVars(map[string]any{"name": "Tobie"}).
+			WillReturn([]any{})
+
+		if _, err := db.Exec("SELECT * FROM user WHERE name = $_name", sql.Named("name", "Tobie")); err != nil {
+			t.Fatal(err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		db, mock, err := surrealmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		db.Close()
+		if _, err := sql.Open(surrealmock.DriverName, mock.DSN()); err == nil || !strings.Contains(err.Error(), "no mock named") {
+			t.Errorf("closed mock still registered: %v", err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		db, mock, err := surrealmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		mock.ExpectQueryMatch(`^THROW`).WillFailStatement("An error occurred: aqua")
+		mock.ExpectQuery("RETURN 1").WillReturnError(&api.APIError{Code: -32602, Message: "Invalid params"})
+
+		if _, err := db.Exec(`THROW "aqua"`); err == nil || err.Error() != "An error occurred: aqua" {
+			t.Errorf("statement error: %v", err)
+		}
+		if _, err := db.Exec("RETURN 1"); err == nil || !strings.Contains(err.Error(), "-32602") {
+			t.Errorf("RPC error: %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Order", func(t *testing.T) {
+		db, mock, err := surrealmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		mock.ExpectQuery("RETURN 1").WillReturn(1)
+		mock.ExpectQuery("RETURN 2").WillReturn(2)
+		if _, err := db.Exec("RETURN 2"); err == nil {
+			t.Error("out of order query was accepted")
+		}
+		if err := mock.ExpectationsWereMet(); err == nil {
+			t.Error("unmet expectations were not reported")
+		}
+
+		db, mock, err = surrealmock.New(surrealmock.Unordered())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		mock.ExpectQuery("RETURN 1").WillReturn(1)
+		mock.ExpectQuery("RETURN 2").WillReturn(2)
+		db.Exec("RETURN 2")
+		db.Exec("RETURN 1")
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("MultipleStatements", func(t *testing.T) {
+		db, mock, err := surrealmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		mock.ExpectQuery("LET $a = 1; SELECT * FROM user;").
+			WillReturn(nil, []map[string]any{{"id": "user:tobie"}})
+		rows, err := db.Query("LET $a = 1; SELECT * FROM user;")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		// Every statement contributes its rows; LET contributes a NULL one.
+		var ids []string
+		for rows.Next() {
+			var id sql.NullString
+			if err := rows.Scan(&id); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id.String)
+		}
+		if strings.Join(ids, ",") != ",user:tobie" {
+			t.Errorf("got %q", ids)
+		}
+	})
+
+	t.Run("Rel", func(t *testing.T) {
+		db, mock, err := surrealmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		mock.ExpectQueryMatch(`^SELECT .* FROM users\b`).
+			WithArgs("Tobie").
+			WillReturn([]map[string]any{{"id": "users:tobie", "name": "Tobie", "age": 42}})
+
+		repo := rel.New(srel.New(db))
+		var user User
+		if err := repo.Find(context.Background(), &user, where.Eq("name", "Tobie")); err != nil {
+			t.Fatal(err)
+		}
+		if user.Age != 42 {
+			t.Errorf("got %+v", user)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Gorm", func(t *testing.T) {
+		db, mock, err := surrealmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		mock.ExpectQuery("DELETE user").WillReturn([]any{})
+
+		gdb, err := gorm.Open(sgorm.New(db), &gorm.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := gdb.Exec("DELETE user").Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,243 @@
+/*
+Package surrealotel traces the driver with OpenTelemetry.
+
+	connector, err := surrealdbdriver.NewConnector(
+		surrealdbdriver.WithDSN(dsn),
+		surrealotel.Instrument(),
+	)
+	db := sql.OpenDB(connector)
+
+This emits a span for connecting, one for every RPC (signin, use, query, ...)
+and, below a query's span, one for each of its statements with the status and
+execution time SurrealDB reported. Spans are children of whatever span is in
+the context passed to QueryContext and friends.
+
+Failed spans carry the error and its classification (see api.Classify) as
+the `error.type` attribute.
+*/
+package surrealotel
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	surrealdbdriver "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/IngwiePhoenix/surrealdb-driver/api"
+	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/IngwiePhoenix/surrealdb-driver/surrealotel"
+
+// Attribute keys. The db.*, server.* and error.type ones follow the
+// OpenTelemetry semantic conventions.
+const (
+	AttrDBSystem        = attribute.Key("db.system.name")
+	AttrDBNamespace     = attribute.Key("db.namespace")
+	AttrDBOperation     = attribute.Key("db.operation.name")
+	AttrDBQueryText     = attribute.Key("db.query.text")
+	AttrServerAddress   = attribute.Key("server.address")
+	AttrServerPort      = attribute.Key("server.port")
+	AttrErrorType       = attribute.Key("error.type")
+	AttrNamespace       = attribute.Key("surrealdb.namespace")
+	AttrDatabase        = attribute.Key("surrealdb.database")
+	AttrStatementIndex  = attribute.Key("surrealdb.statement.index")
+	AttrStatementStatus = attribute.Key("surrealdb.statement.status")
+	AttrStatementTime   = attribute.Key("surrealdb.statement.time")
+)
+
+// Option configures the tracing.
+type Option func(*config)
+
+type config struct {
+	provider       trace.TracerProvider
+	sanitize       func(string) string
+	statementSpans bool
+	attrs          []attribute.KeyValue
+}
+
+// WithTracerProvider uses provider instead of the global one.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// WithSanitizer passes query text through fn before it is recorded, i.e.
+// Sanitize to strip literals. Returning "" leaves the text out entirely.
+func WithSanitizer(fn func(string) string) Option {
+	return func(c *config) {
+		c.sanitize = fn
+	}
+}
+
+// WithoutStatementSpans only traces RPCs, not the statements of queries.
+func WithoutStatementSpans() Option {
+	return func(c *config) {
+		c.statementSpans = false
+	}
+}
+
+// WithAttributes adds attributes to every span.
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(c *config) {
+		c.attrs = append(c.attrs, attrs...)
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{statementSpans: true}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.provider == nil {
+		c.provider = otel.GetTracerProvider()
+	}
+	return c
+}
+
+// Instrument traces the connector's connections. It chains onto the hooks
+// set so far, so pass it after surrealdbdriver.WithHooks.
+func Instrument(opts ...Option) surrealdbdriver.Option {
+	return func(c *surrealdbdriver.SurrealConnector) error {
+		cfg := newConfig(opts)
+		tracer := cfg.provider.Tracer(instrumentationName)
+		prev := c.Hooks
+
+		c.Hooks.BeforeConnect = func(ctx context.Context) context.Context {
+			if prev.BeforeConnect != nil {
+				ctx = prev.BeforeConnect(ctx)
+			}
+			attrs := append(serverAttrs(c), cfg.attrs...)
+			ctx, _ = tracer.Start(ctx, "surrealdb.connect",
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+			)
+			return ctx
+		}
+		c.Hooks.OnConnect = func(ctx context.Context, con *surrealdbdriver.SurrealConn) {
+			trace.SpanFromContext(ctx).End()
+			if prev.OnConnect != nil {
+				prev.OnConnect(ctx, con)
+			}
+		}
+		c.Hooks.OnConnectError = func(ctx context.Context, err error) {
+			span := trace.SpanFromContext(ctx)
+			recordError(span, err)
+			span.End()
+			if prev.OnConnectError != nil {
+				prev.OnConnectError(ctx, err)
+			}
+		}
+
+		c.Middleware = append(c.Middleware, &middleware{cfg: cfg, tracer: tracer, connector: c})
+		return nil
+	}
+}
+
+// NewMiddleware returns just the RPC and statement tracing, for use with
+// surrealdbdriver.WithMiddleware.
+func NewMiddleware(opts ...Option) surrealdbdriver.Middleware {
+	cfg := newConfig(opts)
+	return &middleware{cfg: cfg, tracer: cfg.provider.Tracer(instrumentationName)}
+}
+
+type middleware struct {
+	cfg       *config
+	tracer    trace.Tracer
+	connector *surrealdbdriver.SurrealConnector // Only to describe the server; may be nil
+}
+
+func (m *middleware) Before(ctx context.Context, req *api.Request) (context.Context, error) {
+	attrs := []attribute.KeyValue{
+		AttrDBSystem.String("surrealdb"),
+		AttrDBOperation.String(string(req.Method)),
+	}
+	attrs = append(attrs, serverAttrs(m.connector)...)
+	if con, ok := surrealdbdriver.ConnFromContext(ctx); ok {
+		ns, db := con.Namespace(), con.Database()
+		attrs = append(attrs, AttrNamespace.String(ns), AttrDatabase.String(db))
+		if ns != "" || db != "" {
+			attrs = append(attrs, AttrDBNamespace.String(ns+"/"+db))
+		}
+	}
+	if req.Method == api.APIMethodQuery {
+		if params, ok := req.Params.([]any); ok && len(params) > 0 {
+			if sql, ok := params[0].(string); ok {
+				if m.cfg.sanitize != nil {
+					sql = m.cfg.sanitize(sql)
+				}
+				if sql != "" {
+					attrs = append(attrs, AttrDBQueryText.String(sql))
+				}
+			}
+		}
+	}
+	attrs = append(attrs, m.cfg.attrs...)
+	ctx, _ = m.tracer.Start(ctx, "surrealdb."+string(req.Method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx, nil
+}
+
+func (m *middleware) After(ctx context.Context, req *api.Request, resp *api.Response, err error, d time.Duration) {
+	span := trace.SpanFromContext(ctx)
+	end := time.Now()
+	if m.cfg.statementSpans && resp != nil && req.Method == api.APIMethodQuery {
+		m.statementSpans(ctx, resp.Result, end.Add(-d))
+	}
+	if err != nil {
+		recordError(span, err)
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+// One span per statement. SurrealDB runs them one after another and only
+// reports how long each took, so they are laid out back to back from the
+// start of the RPC.
+func (m *middleware) statementSpans(ctx context.Context, result gjson.Result, start time.Time) {
+	result.ForEach(func(idx, stmt gjson.Result) bool {
+		took, _ := time.ParseDuration(stmt.Get("time").String())
+		status := stmt.Get("status").String()
+		_, span := m.tracer.Start(ctx, "surrealdb.statement",
+			trace.WithTimestamp(start),
+			trace.WithAttributes(
+				AttrStatementIndex.Int64(idx.Int()),
+				AttrStatementStatus.String(status),
+				AttrStatementTime.String(stmt.Get("time").String()),
+			),
+		)
+		if status != "OK" {
+			recordError(span, &api.QueryError{
+				Statement: int(idx.Int()),
+				Message:   stmt.Get("result").String(),
+				Time:      stmt.Get("time").String(),
+			})
+		}
+		start = start.Add(took)
+		span.End(trace.WithTimestamp(start))
+		return true
+	})
+}
+
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	span.SetAttributes(AttrErrorType.String(string(api.Classify(err))))
+}
+
+func serverAttrs(c *surrealdbdriver.SurrealConnector) []attribute.KeyValue {
+	if c == nil || c.Creds == nil || c.Creds.URL == nil {
+		return nil
+	}
+	attrs := []attribute.KeyValue{AttrServerAddress.String(c.Creds.URL.Hostname())}
+	if port, err := strconv.Atoi(c.Creds.URL.Port()); err == nil {
+		attrs = append(attrs, AttrServerPort.Int(port))
+	}
+	return attrs
+}
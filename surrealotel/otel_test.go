@@ -0,0 +1,106 @@
+package surrealotel_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	surrealdbdriver "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealotel"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealtest"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInstrument(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	srv.OnQuery("CREATE person SET name = 'Tobie'; CREATE person:tobie;").
+		Return([]map[string]any{{"id": "person:abc"}}).
+		ReturnError("Database record `person:tobie` already exists")
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	connector, err := surrealdbdriver.NewConnector(
+		surrealdbdriver.WithDSN(srv.DSN()),
+		surrealotel.Instrument(
+			surrealotel.WithTracerProvider(provider),
+			surrealotel.WithSanitizer(surrealotel.Sanitize),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	_, err = db.ExecContext(ctx, "CREATE person SET name = 'Tobie'; CREATE person:tobie;")
+	parent.End()
+	if err == nil {
+		t.Fatal("statement error was lost")
+	}
+
+	spans := map[string][]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = append(spans[s.Name()], s)
+	}
+	for _, name := range []string{"surrealdb.connect", "surrealdb.signin", "surrealdb.use", "surrealdb.query"} {
+		if len(spans[name]) != 1 {
+			t.Errorf("%d %s spans, want 1", len(spans[name]), name)
+		}
+	}
+	if t.Failed() {
+		return
+	}
+
+	query := spans["surrealdb.query"][0]
+	if query.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("query span is not a child of the caller's span")
+	}
+	attrs := attrMap(query.Attributes())
+	if attrs["db.query.text"] != "CREATE person SET name = ?; CREATE person:tobie;" {
+		t.Errorf("query text: %q", attrs["db.query.text"])
+	}
+	if attrs["surrealdb.namespace"] != "test" || attrs["surrealdb.database"] != "test" {
+		t.Errorf("namespace/database: %v", attrs)
+	}
+	if attrs["error.type"] != "conflict" {
+		t.Errorf("error.type: %q", attrs["error.type"])
+	}
+
+	statements := spans["surrealdb.statement"]
+	if len(statements) != 2 {
+		t.Fatalf("%d statement spans, want 2", len(statements))
+	}
+	for _, s := range statements {
+		if s.Parent().SpanID() != query.SpanContext().SpanID() {
+			t.Error("statement span is not a child of the query span")
+		}
+	}
+	if attrMap(statements[1].Attributes())["surrealdb.statement.status"] != "ERR" {
+		t.Error("second statement did not fail")
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	cases := map[string]string{
+		`SELECT * FROM user WHERE name = "Tobie" AND age > 18`:   `SELECT * FROM user WHERE name = ? AND age > ?`,
+		`SELECT * FROM user:tobie WHERE created > d'2024-01-01'`: `SELECT * FROM user:tobie WHERE created > ?`,
+		`SELECT * FROM $table WHERE x = 'it\'s' -- comment`:      `SELECT * FROM $table WHERE x = ? `,
+		"SELECT `field 1` FROM t1 LIMIT 10":                      "SELECT `field 1` FROM t1 LIMIT ?",
+	}
+	for in, want := range cases {
+		if got := surrealotel.Sanitize(in); got != want {
+			t.Errorf("Sanitize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func attrMap(attrs []attribute.KeyValue) map[string]string {
+	out := map[string]string{}
+	for _, a := range attrs {
+		out[string(a.Key)] = a.Value.Emit()
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package surrealotel
+
+import (
+	"strings"
+)
+
+// Sanitize replaces string and number literals in SurrealQL with `?`, so that
+// values written inline do not end up in traces. Parameters ($name),
+// identifiers and keywords are kept. Comments are dropped.
+func Sanitize(sql string) string {
+	out := strings.Builder{}
+	out.Grow(len(sql))
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"':
+			// Prefixed strings (r"", d'', u'', s"") lose their prefix, too.
+			if n := out.Len(); n > 0 && isPrefix(out.String()[n-1]) && (n == 1 || !isIdent(out.String()[n-2])) {
+				s := out.String()[:n-1]
+				out.Reset()
+				out.WriteString(s)
+			}
+			i = skipString(sql, i)
+			out.WriteByte('?')
+		case c == '`' || strings.HasPrefix(sql[i:], "⟨"):
+			// Escaped identifiers are not values.
+			closing := "`"
+			if c != '`' {
+				closing = "⟩"
+			}
+			end := strings.Index(sql[i+1:], closing)
+			if end < 0 {
+				out.WriteString(sql[i:])
+				return out.String()
+			}
+			end += i + 1 + len(closing)
+			out.WriteString(sql[i:end])
+			i = end - 1
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-', c == '/' && i+1 < len(sql) && sql[i+1] == '/', c == '#':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			if i < len(sql) {
+				out.WriteByte('\n')
+			}
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return out.String()
+			}
+			i += end + 3
+			out.WriteByte(' ')
+		case c >= '0' && c <= '9' && (i == 0 || !isIdent(sql[i-1])):
+			for i+1 < len(sql) && (isIdent(sql[i+1]) || sql[i+1] == '.') {
+				i++
+			}
+			out.WriteByte('?')
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+// Returns the index of the closing quote of the string starting at i.
+func skipString(sql string, i int) int {
+	quote := sql[i]
+	for j := i + 1; j < len(sql); j++ {
+		switch sql[j] {
+		case '\\':
+			j++
+		case quote:
+			return j
+		}
+	}
+	return len(sql) - 1
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || c == '$' || c == ':' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isPrefix(c byte) bool {
+	switch c {
+	case 'r', 'd', 'u', 's', 'b':
+		return true
+	}
+	return false
+}
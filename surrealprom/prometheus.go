@@ -0,0 +1,186 @@
+/*
+Package surrealprom exports the driver's measurements to Prometheus.
+
+	metrics := surrealprom.New()
+	prometheus.MustRegister(metrics)
+	connector, err := surrealdbdriver.NewConnector(
+		surrealdbdriver.WithDSN(dsn),
+		surrealdbdriver.WithMetrics(metrics),
+	)
+
+These metrics are collected (with the default namespace):
+
+	surrealdb_requests_total{method,error_class}
+	surrealdb_request_duration_seconds{method}
+	surrealdb_requests_in_flight{method}
+	surrealdb_statements_total{error_class}
+	surrealdb_statement_duration_seconds{error_class}
+	surrealdb_connects_total{error_class}
+	surrealdb_reconnects_total
+	surrealdb_bad_connections_total
+	surrealdb_live_queries
+	surrealdb_bytes_total{direction}
+
+error_class is one of api.ErrorClass, or "ok".
+*/
+package surrealprom
+
+import (
+	"time"
+
+	surrealdbdriver "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/IngwiePhoenix/surrealdb-driver/api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Option configures the metrics.
+type Option func(*config)
+
+type config struct {
+	namespace   string
+	constLabels prometheus.Labels
+	buckets     []float64
+}
+
+// WithNamespace prefixes the metrics' names with namespace instead of
+// "surrealdb".
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace
+	}
+}
+
+// WithConstLabels adds labels to every metric, i.e. to tell apart several
+// connectors.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(c *config) {
+		c.constLabels = labels
+	}
+}
+
+// WithBuckets sets the upper bounds of the duration histograms, in seconds.
+func WithBuckets(buckets []float64) Option {
+	return func(c *config) {
+		c.buckets = buckets
+	}
+}
+
+// Metrics is both a surrealdbdriver.Metrics and a prometheus.Collector.
+type Metrics struct {
+	requests          *prometheus.CounterVec
+	requestDuration   *prometheus.HistogramVec
+	inflight          *prometheus.GaugeVec
+	statements        *prometheus.CounterVec
+	statementDuration *prometheus.HistogramVec
+	connects          *prometheus.CounterVec
+	reconnects        prometheus.Counter
+	bad               prometheus.Counter
+	live              prometheus.Gauge
+	bytes             *prometheus.CounterVec
+}
+
+var _ surrealdbdriver.Metrics = (*Metrics)(nil)
+var _ prometheus.Collector = (*Metrics)(nil)
+
+// New creates the metrics. They still need to be registered.
+func New(opts ...Option) *Metrics {
+	c := &config{namespace: "surrealdb", buckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(c)
+	}
+	counter := func(name, help string, labels ...string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: c.namespace, Name: name, Help: help, ConstLabels: c.constLabels,
+		}, labels)
+	}
+	histogram := func(name, help string, labels ...string) *prometheus.HistogramVec {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: c.namespace, Name: name, Help: help, ConstLabels: c.constLabels, Buckets: c.buckets,
+		}, labels)
+	}
+	return &Metrics{
+		requests:          counter("requests_total", "RPCs sent, by method and outcome.", "method", "error_class"),
+		requestDuration:   histogram("request_duration_seconds", "Time from sending an RPC until its response arrived.", "method"),
+		statements:        counter("statements_total", "Statements of queries run, by outcome.", "error_class"),
+		statementDuration: histogram("statement_duration_seconds", "Execution time of statements as reported by SurrealDB.", "error_class"),
+		connects:          counter("connects_total", "Connections opened, by outcome.", "error_class"),
+		bytes:             counter("bytes_total", "Bytes sent and received.", "direction"),
+		inflight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: c.namespace, Name: "requests_in_flight", Help: "RPCs awaiting their response.", ConstLabels: c.constLabels,
+		}, []string{"method"}),
+		reconnects: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: c.namespace, Name: "reconnects_total", Help: "Connections that replaced one that went bad.", ConstLabels: c.constLabels,
+		}),
+		bad: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: c.namespace, Name: "bad_connections_total", Help: "Connections that turned out to be unusable.", ConstLabels: c.constLabels,
+		}),
+		live: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: c.namespace, Name: "live_queries", Help: "Running live queries.", ConstLabels: c.constLabels,
+		}),
+	}
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.requests, m.requestDuration, m.inflight, m.statements, m.statementDuration,
+		m.connects, m.reconnects, m.bad, m.live, m.bytes,
+	}
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+func (m *Metrics) RequestStarted(method api.APIMethod) {
+	m.inflight.WithLabelValues(string(method)).Inc()
+}
+
+func (m *Metrics) RequestDone(method api.APIMethod, d time.Duration, class api.ErrorClass) {
+	m.inflight.WithLabelValues(string(method)).Dec()
+	m.requests.WithLabelValues(string(method), classLabel(class)).Inc()
+	m.requestDuration.WithLabelValues(string(method)).Observe(d.Seconds())
+}
+
+func (m *Metrics) StatementDone(d time.Duration, class api.ErrorClass) {
+	m.statements.WithLabelValues(classLabel(class)).Inc()
+	m.statementDuration.WithLabelValues(classLabel(class)).Observe(d.Seconds())
+}
+
+func (m *Metrics) Connected(class api.ErrorClass, reconnect bool) {
+	m.connects.WithLabelValues(classLabel(class)).Inc()
+	if reconnect {
+		m.reconnects.Inc()
+	}
+}
+
+func (m *Metrics) ConnectionBad() {
+	m.bad.Inc()
+}
+
+func (m *Metrics) LiveQueries(delta int) {
+	m.live.Add(float64(delta))
+}
+
+func (m *Metrics) Transferred(sent, received int) {
+	if sent > 0 {
+		m.bytes.WithLabelValues("sent").Add(float64(sent))
+	}
+	if received > 0 {
+		m.bytes.WithLabelValues("received").Add(float64(received))
+	}
+}
+
+func classLabel(class api.ErrorClass) string {
+	if class == api.ErrorClassNone {
+		return "ok"
+	}
+	return string(class)
+}
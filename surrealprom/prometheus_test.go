@@ -0,0 +1,66 @@
+package surrealprom_test
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	surrealdbdriver "github.com/IngwiePhoenix/surrealdb-driver"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealprom"
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	srv.OnQuery("CREATE person:tobie; CREATE person:tobie;").
+		Return([]map[string]any{{"id": "person:tobie"}}).
+		ReturnError("Database record `person:tobie` already exists")
+
+	metrics := surrealprom.New(surrealprom.WithConstLabels(prometheus.Labels{"db": "test"}))
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(metrics)
+
+	connector, err := surrealdbdriver.NewConnector(
+		surrealdbdriver.WithDSN(srv.DSN()),
+		surrealdbdriver.WithMetrics(metrics),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	if _, err := db.Exec("CREATE person:tobie; CREATE person:tobie;"); err == nil {
+		t.Fatal("statement error was lost")
+	}
+	db.Close()
+
+	expected := `
+# HELP surrealdb_requests_total RPCs sent, by method and outcome.
+# TYPE surrealdb_requests_total counter
+surrealdb_requests_total{db="test",error_class="conflict",method="query"} 1
+surrealdb_requests_total{db="test",error_class="ok",method="invalidate"} 1
+surrealdb_requests_total{db="test",error_class="ok",method="signin"} 1
+surrealdb_requests_total{db="test",error_class="ok",method="use"} 1
+# HELP surrealdb_statements_total Statements of queries run, by outcome.
+# TYPE surrealdb_statements_total counter
+surrealdb_statements_total{db="test",error_class="conflict"} 1
+surrealdb_statements_total{db="test",error_class="ok"} 1
+# HELP surrealdb_requests_in_flight RPCs awaiting their response.
+# TYPE surrealdb_requests_in_flight gauge
+surrealdb_requests_in_flight{db="test",method="invalidate"} 0
+surrealdb_requests_in_flight{db="test",method="query"} 0
+surrealdb_requests_in_flight{db="test",method="signin"} 0
+surrealdb_requests_in_flight{db="test",method="use"} 0
+# HELP surrealdb_connects_total Connections opened, by outcome.
+# TYPE surrealdb_connects_total counter
+surrealdb_connects_total{db="test",error_class="ok"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"surrealdb_requests_total", "surrealdb_requests_in_flight", "surrealdb_statements_total", "surrealdb_connects_total"); err != nil {
+		t.Error(err)
+	}
+	if n, err := testutil.GatherAndCount(reg, "surrealdb_bytes_total"); err != nil || n != 2 {
+		t.Errorf("%d bytes_total series, %v", n, err)
+	}
+}
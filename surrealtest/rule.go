@@ -0,0 +1,169 @@
+package surrealtest
+
+import (
+	"regexp"
+	"time"
+)
+
+// Statement is one entry of a `query` response.
+type Statement struct {
+	Result any    `json:"result"`
+	Status string `json:"status"`
+	Time   string `json:"time"`
+	live   bool   // Result is replaced with a fresh live query ID
+}
+
+// OK is a successful statement returning result.
+func OK(result any) Statement {
+	return Statement{Result: result, Status: "OK", Time: "1ms"}
+}
+
+// ERR is a failed statement; SurrealDB reports the message as its result.
+func ERR(message string) Statement {
+	return Statement{Result: message, Status: "ERR", Time: "1ms"}
+}
+
+// Rule scripts the answer to matching requests. The newest matching rule
+// wins, so tests can override earlier rules.
+type Rule struct {
+	method     string
+	text       string
+	pattern    *regexp.Regexp
+	statements []Statement
+	result     any
+	err        error
+	handler    func(Request) (any, error)
+	delay      time.Duration
+	once       bool
+}
+
+// On adds a rule for every request of an RPC method, i.e. "signin" or "select".
+func (s *Server) On(method string) *Rule {
+	return s.add(&Rule{method: method})
+}
+
+// OnQuery adds a rule for a query with exactly this SurrealQL. Whitespace is
+// collapsed before comparing.
+func (s *Server) OnQuery(sql string) *Rule {
+	return s.add(&Rule{method: "query", text: normalize(sql)})
+}
+
+// OnQueryMatch adds a rule for queries matching a regular expression. It
+// panics if the expression is invalid.
+func (s *Server) OnQueryMatch(pattern string) *Rule {
+	return s.add(&Rule{method: "query", pattern: regexp.MustCompile(pattern)})
+}
+
+func (s *Server) add(r *Rule) *Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append(s.rules, r)
+	return r
+}
+
+// Return sets the result. For queries, every value is the result of one
+// statement, so Return(a, b) answers a query of two statements. For other
+// methods, a single value is returned as is and several as an array.
+func (r *Rule) Return(results ...any) *Rule {
+	if r.method == "query" {
+		for _, result := range results {
+			r.statements = append(r.statements, OK(result))
+		}
+		return r
+	}
+	if len(results) == 1 {
+		r.result = results[0]
+	} else {
+		r.result = results
+	}
+	return r
+}
+
+// ReturnError makes a query statement fail with message. For other methods,
+// it is the same as Fail(-32000, message).
+func (r *Rule) ReturnError(message string) *Rule {
+	if r.method == "query" {
+		r.statements = append(r.statements, ERR(message))
+		return r
+	}
+	return r.Fail(-32000, message)
+}
+
+// ReturnStatements appends arbitrary statements to a query's response.
+func (r *Rule) ReturnStatements(statements ...Statement) *Rule {
+	r.statements = append(r.statements, statements...)
+	return r
+}
+
+// ReturnLive answers a `LIVE SELECT` statement: a live query is registered
+// and its ID returned, so that Server.Notify can push to it.
+func (r *Rule) ReturnLive() *Rule {
+	r.statements = append(r.statements, Statement{Status: "OK", Time: "1ms", live: true})
+	return r
+}
+
+// Fail answers with an RPC error instead of a result.
+func (r *Rule) Fail(code int, message string) *Rule {
+	r.err = &Error{Code: code, Message: message}
+	return r
+}
+
+// Handle answers with whatever fn returns. For queries, fn should return a
+// []Statement.
+func (r *Rule) Handle(fn func(Request) (any, error)) *Rule {
+	r.handler = fn
+	return r
+}
+
+// Delay holds the answer back, i.e. to test timeouts or shutdown.
+func (r *Rule) Delay(d time.Duration) *Rule {
+	r.delay = d
+	return r
+}
+
+// Once removes the rule after it matched for the first time.
+func (r *Rule) Once() *Rule {
+	r.once = true
+	return r
+}
+
+func (r *Rule) matches(req Request) bool {
+	if r.method != req.Method {
+		return false
+	}
+	if r.text == "" && r.pattern == nil {
+		return true
+	}
+	sql, _ := req.Query()
+	if r.pattern != nil {
+		return r.pattern.MatchString(sql)
+	}
+	return r.text == normalize(sql)
+}
+
+func (r *Rule) answer(s *Server, req Request) (any, error) {
+	if r.delay > 0 {
+		time.Sleep(r.delay)
+	}
+	if r.handler != nil {
+		return r.handler(req)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.method != "query" {
+		return r.result, nil
+	}
+	out := make([]Statement, len(r.statements))
+	for i, stmt := range r.statements {
+		if stmt.live {
+			if req.conn == nil {
+				stmt = ERR("Live queries need a WebSocket connection")
+			} else {
+				stmt.Result = s.startLive(req.conn)
+			}
+		}
+		out[i] = stmt
+	}
+	return out, nil
+}
@@ -0,0 +1,394 @@
+/*
+Package surrealtest runs an in-process stand-in for SurrealDB, so that code
+using the driver can be tested without a real server.
+
+The server speaks SurrealDB's JSON RPC over WebSockets (and plain HTTP) and
+answers every request from a script:
+
+	srv := surrealtest.NewServer(t)
+	srv.OnQuery("SELECT * FROM user").Return([]map[string]any{
+		{"id": "user:tobie", "name": "Tobie"},
+	})
+	srv.OnQueryMatch(`^THROW`).ReturnError("An error occurred: aqua")
+
+	db, _ := sql.Open("surrealdb", srv.DSN())
+
+Methods without a rule get a sensible default answer (a token for signin,
+null for use/let/kill, a fresh UUID for live, ...). Queries without a rule
+are answered with an RPC error and fail the test.
+
+Everything that was sent is recorded; see Requests and Queries.
+*/
+package surrealtest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/gofrs/uuid/v5"
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+// The version reported by the `version` method and the /version endpoint.
+const Version = "surrealdb-2.1.0"
+
+// Request is a request as the server received it.
+type Request struct {
+	ID     string
+	Method string
+	Params gjson.Result
+	conn   *conn
+}
+
+// Query returns the SurrealQL and variables of a `query` request.
+func (r Request) Query() (string, map[string]any) {
+	vars, _ := r.Params.Get("1").Value().(map[string]any)
+	return r.Params.Get("0").String(), vars
+}
+
+// Error is an RPC level error. Handlers may return one to control the code;
+// any other error is reported with code -32000.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Server is a fake SurrealDB. Create one with NewServer.
+type Server struct {
+	URL     string // ws://host:port/rpc
+	HTTPURL string // http://host:port
+
+	t        testing.TB
+	http     *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu       sync.Mutex
+	rules    []*Rule
+	requests []Request
+	conns    map[*conn]bool
+	lives    map[string]*conn
+}
+
+// NewServer starts a server that is closed once the test ends. Unexpected
+// queries are reported to t.
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		t:        t,
+		upgrader: websocket.Upgrader{Subprotocols: []string{"json"}},
+		conns:    map[*conn]bool{},
+		lives:    map[string]*conn{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rpc", s.serveRPC)
+	mux.HandleFunc("/sql", s.serveSQL)
+	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {})
+	mux.HandleFunc("/version", func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, Version)
+	})
+	s.http = httptest.NewServer(mux)
+	s.HTTPURL = s.http.URL
+	s.URL = "ws" + strings.TrimPrefix(s.http.URL, "http") + "/rpc"
+	t.Cleanup(s.Close)
+	return s
+}
+
+// DSN returns a DSN signing in as root:root into the namespace and database
+// "test".
+func (s *Server) DSN() string {
+	return "ws://root:root@" + strings.TrimPrefix(s.http.URL, "http://") + "/rpc?method=root&ns=test&db=test"
+}
+
+// Close disconnects every client and stops the server.
+func (s *Server) Close() {
+	s.mu.Lock()
+	for c := range s.conns {
+		c.ws.Close()
+	}
+	s.mu.Unlock()
+	s.http.Close()
+}
+
+// CloseConnections drops every client connection without a close frame, as a
+// crashing server or a broken network would.
+func (s *Server) CloseConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		c.ws.UnderlyingConn().Close()
+	}
+}
+
+// Requests returns everything that was received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Sent returns the requests of one method, in order.
+func (s *Server) Sent(method string) []Request {
+	out := []Request{}
+	for _, r := range s.Requests() {
+		if r.Method == method {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Queries returns the SurrealQL of every `query` request, in order.
+func (s *Server) Queries() []string {
+	out := []string{}
+	for _, r := range s.Sent("query") {
+		sql, _ := r.Query()
+		out = append(out, sql)
+	}
+	return out
+}
+
+// Reset forgets all rules, recorded requests and live queries; Notify fails
+// for live queries started before.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = nil
+	s.requests = nil
+	s.lives = map[string]*conn{}
+}
+
+// LiveQueries lists the live queries that were started and not killed yet.
+func (s *Server) LiveQueries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, 0, len(s.lives))
+	for id := range s.lives {
+		out = append(out, id)
+	}
+	return out
+}
+
+// Notify pushes a live notification to the connection that started the live
+// query. action is CREATE, UPDATE or DELETE.
+func (s *Server) Notify(id, action string, result any) error {
+	s.mu.Lock()
+	c, ok := s.lives[id]
+	s.mu.Unlock()
+	if !ok {
+		return errors.New("surrealtest: no such live query: " + id)
+	}
+	return c.write(map[string]any{
+		"result": map[string]any{"action": action, "id": id, "result": result},
+	})
+}
+
+// Registers a live query for c and returns its ID.
+func (s *Server) startLive(c *conn) string {
+	id := uuid.Must(uuid.NewV4()).String()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lives[id] = c
+	return id
+}
+
+func (s *Server) serveRPC(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		s.serveWS(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.handle(nil, body))
+}
+
+// POST /sql takes the SurrealQL as its body and answers with the statement
+// results, like SurrealDB's HTTP endpoint.
+func (s *Server) serveSQL(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params, _ := json.Marshal([]any{string(body)})
+	req := Request{Method: "query", Params: gjson.ParseBytes(params)}
+	result, err := s.dispatch(req)
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(toError(err))
+		return
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+func (s *Server) serveWS(w http.ResponseWriter, r *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &conn{ws: ws}
+	s.mu.Lock()
+	s.conns[c] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		for id, owner := range s.lives {
+			if owner == c {
+				delete(s.lives, id)
+			}
+		}
+		s.mu.Unlock()
+		ws.Close()
+	}()
+
+	for {
+		_, msg, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		// Requests are recorded in order, but answered concurrently like the
+		// real server does; a slow rule must not hold up the others.
+		req, ok := s.record(c, msg)
+		if !ok {
+			c.write(map[string]any{"error": &Error{Code: -32700, Message: "Parse error"}})
+			continue
+		}
+		go func() { c.write(s.answer(req)) }()
+	}
+}
+
+// Parses and records one RPC message.
+func (s *Server) record(c *conn, msg []byte) (Request, bool) {
+	if !gjson.ValidBytes(msg) {
+		return Request{}, false
+	}
+	parsed := gjson.ParseBytes(msg)
+	req := Request{
+		ID:     parsed.Get("id").String(),
+		Method: parsed.Get("method").String(),
+		Params: parsed.Get("params"),
+		conn:   c,
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+	return req, true
+}
+
+// Parses, records and answers one RPC message.
+func (s *Server) handle(c *conn, msg []byte) map[string]any {
+	req, ok := s.record(c, msg)
+	if !ok {
+		return map[string]any{"error": &Error{Code: -32700, Message: "Parse error"}}
+	}
+	return s.answer(req)
+}
+
+// Builds the response frame for req.
+func (s *Server) answer(req Request) map[string]any {
+	out := map[string]any{"id": req.ID}
+	result, err := s.dispatch(req)
+	if err != nil {
+		out["error"] = toError(err)
+	} else {
+		out["result"] = result
+	}
+	return out
+}
+
+// Finds the newest rule matching req, or falls back to the default answer.
+func (s *Server) dispatch(req Request) (any, error) {
+	s.mu.Lock()
+	var rule *Rule
+	for i := len(s.rules) - 1; i >= 0; i-- {
+		if s.rules[i].matches(req) {
+			rule = s.rules[i]
+			if rule.once {
+				s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			}
+			break
+		}
+	}
+	s.mu.Unlock()
+	if rule != nil {
+		return rule.answer(s, req)
+	}
+	return s.fallback(req)
+}
+
+func (s *Server) fallback(req Request) (any, error) {
+	switch req.Method {
+	case "signin", "signup":
+		return "surrealtest-token", nil
+	case "version":
+		return Version, nil
+	case "live":
+		if req.conn == nil {
+			return nil, &Error{Code: -32000, Message: "Live queries need a WebSocket connection"}
+		}
+		return s.startLive(req.conn), nil
+	case "kill":
+		s.mu.Lock()
+		delete(s.lives, req.Params.Get("0").String())
+		s.mu.Unlock()
+		return nil, nil
+	case "select", "create", "insert", "update", "upsert", "merge", "patch", "delete", "relate", "insert_relation":
+		return []any{}, nil
+	case "query":
+		sql, _ := req.Query()
+		s.t.Errorf("surrealtest: unexpected query: %s", sql)
+		return nil, &Error{Code: -32000, Message: "surrealtest: unexpected query: " + sql}
+	default:
+		return nil, nil
+	}
+}
+
+func toError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &Error{Code: -32000, Message: err.Error()}
+}
+
+// A client connection. Responses and notifications are written from several
+// goroutines, gorilla/websocket wants one writer at a time.
+type conn struct {
+	ws *websocket.Conn
+	mu sync.Mutex
+}
+
+func (c *conn) write(v any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ws.WriteJSON(v)
+}
+
+// Collapses whitespace so that queries can be matched regardless of
+// indentation.
+func normalize(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
@@ -0,0 +1,76 @@
+package surrealtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IngwiePhoenix/surrealdb-driver/surrealtest"
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+// A delayed answer must not hold up the requests sent after it on the same
+// connection.
+func TestDelayAnswersOutOfOrder(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	srv.OnQuery("SELECT * FROM slow").Return([]any{}).Delay(time.Second)
+	srv.OnQuery("SELECT * FROM fast").Return([]any{})
+
+	ws, _, err := websocket.DefaultDialer.Dial(srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	for _, msg := range []string{
+		`{"id":"1","method":"query","params":["SELECT * FROM slow",{}]}`,
+		`{"id":"2","method":"query","params":["SELECT * FROM fast",{}]}`,
+	} {
+		if err := ws.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ws.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	_, msg, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatalf("no answer before the delayed one: %v", err)
+	}
+	if id := gjson.GetBytes(msg, "id").String(); id != "2" {
+		t.Errorf("first answer is to %s: %s", id, msg)
+	}
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, msg, err = ws.ReadMessage(); err != nil {
+		t.Fatal(err)
+	} else if id := gjson.GetBytes(msg, "id").String(); id != "1" {
+		t.Errorf("second answer is to %s: %s", id, msg)
+	}
+}
+
+func TestResetForgetsLiveQueries(t *testing.T) {
+	srv := surrealtest.NewServer(t)
+	ws, _, err := websocket.DefaultDialer.Dial(srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	if err := ws.WriteMessage(websocket.TextMessage, []byte(`{"id":"1","method":"live","params":["person"]}`)); err != nil {
+		t.Fatal(err)
+	}
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, msg, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := gjson.GetBytes(msg, "result").String()
+	if lives := srv.LiveQueries(); len(lives) != 1 || lives[0] != id {
+		t.Fatalf("live queries %v, started %s", lives, id)
+	}
+
+	srv.Reset()
+	if lives := srv.LiveQueries(); len(lives) != 0 {
+		t.Errorf("live queries after Reset: %v", lives)
+	}
+	if err := srv.Notify(id, "CREATE", map[string]any{"id": "person:1"}); err == nil {
+		t.Error("notified a live query of before Reset")
+	}
+}
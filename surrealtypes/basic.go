@@ -1,13 +1,9 @@
 package surrealtypes
 
 import (
-	"github.com/clok/kemba"
 	geojson "github.com/paulmach/go.geojson"
 )
 
-// A small debug helper
-var localKemba = kemba.New("surrealdb:surrealtypes")
-
 //
 // # SurrealDB Type Mapping
 // These types are a mapping of the SurrealDB types into Go.
@@ -34,7 +30,7 @@ type Float = float64
 
 // ### Objects
 // type Object = gjson.Result
-type Geometry = geojson.Geometry
+type Geometry = geojson.Geometry // See GeometryValue for types of each kind
 type Literal = []rune // TODO: Go has no type unions...so what do?
 type Range = any      // TODO: this needs a custom type
 // type Record = Object     // TODO: Actually, this isn't true. in json its string, in db its object!
@@ -4,11 +4,17 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/goccy/go-json"
 )
 
+// DateTime is a SurrealDB datetime: a time.Time in UTC, with nanoseconds.
+// It is written as RFC 3339 with as many fractional digits as needed, i.e.
+// 2024-01-02T03:04:05.123456789Z, and read back from that, from other time
+// zones, which are converted to UTC, and from dates alone, which are
+// midnight UTC.
 type DateTime struct {
 	time.Time
 }
@@ -18,30 +24,178 @@ var _ json.Unmarshaler = (*DateTime)(nil)
 var _ driver.Valuer = (*DateTime)(nil)
 var _ sql.Scanner = (*DateTime)(nil)
 var _ SurrealMarshalable = (*DateTime)(nil)
+var _ SurrealParam = (*DateTime)(nil)
 
-func (t *DateTime) MarshalSurreal() ([]byte, error) {
-	out := []byte{}
-	tstr := t.Time.Format(time.RFC3339)
-	out = append(out, 'd', byte('\''))
-	out = append(out, []byte(tstr)...)
-	out = append(out, byte('\''))
-	return out, nil
+// NewDateTime is t in UTC, without its monotonic clock reading.
+func NewDateTime(t time.Time) DateTime {
+	return DateTime{Time: t.Round(0).UTC()}
 }
 
+// Layouts of datetimes, most likely first. One without a zone is UTC.
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	time.DateOnly,
+}
+
+// ParseDateTime reads a datetime as SurrealDB writes it, as RFC 3339 with or
+// without fractional seconds, or a date like 2024-01-02. It may be wrapped
+// in d'…', as in SurrealQL.
+func ParseDateTime(s string) (DateTime, error) {
+	in := strings.TrimSpace(s)
+	if len(in) >= 3 && in[0] == 'd' && (in[1] == '\'' || in[1] == '"') && in[len(in)-1] == in[1] {
+		in = in[2 : len(in)-1]
+	}
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, in); err == nil {
+			return NewDateTime(t), nil
+		}
+	}
+	return DateTime{}, fmt.Errorf("datetime %q: neither RFC 3339 nor a date", s)
+}
+
+// String writes the time in UTC, as RFC 3339 with nanoseconds.
+func (t DateTime) String() string {
+	return t.Time.UTC().Format(time.RFC3339Nano)
+}
+
+func (t DateTime) MarshalJSON() ([]byte, error) {
+	return json.MarshalNoEscape(t.String())
+}
+
+// UnmarshalJSON reads a string, see ParseDateTime; null is the zero time.
+func (t *DateTime) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*t = DateTime{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseDateTime(s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalSurreal writes the time as a datetime literal, i.e.
+// d'2024-01-02T03:04:05.123456789Z'.
+func (t DateTime) MarshalSurreal() ([]byte, error) {
+	return []byte("d'" + t.String() + "'"), nil
+}
+
+// Scan reads a time.Time, or a string or []byte as ParseDateTime does; a
+// []byte may also hold a JSON string.
 func (t *DateTime) Scan(src any) error {
 	switch data := src.(type) {
+	case time.Time:
+		*t = NewDateTime(data)
+		return nil
 	case string:
-		tmp, err := time.Parse(time.RFC3339, data)
+		parsed, err := ParseDateTime(data)
 		if err != nil {
 			return err
 		}
-		t.Time = tmp
+		*t = parsed
 		return nil
+	case []byte:
+		if strings.HasPrefix(string(data), `"`) {
+			return t.UnmarshalJSON(data)
+		}
+		return t.Scan(string(data))
 	default:
-		return fmt.Errorf("input must be string, found %T", src)
+		return fmt.Errorf("input must be time.Time, string or []byte, found %T", src)
 	}
 }
 
-func (t *DateTime) Value() (driver.Value, error) {
-	return t.MarshalJSON()
+// Value implements driver.Valuer, giving the time in UTC.
+func (t DateTime) Value() (driver.Value, error) {
+	return t.Time.UTC(), nil
+}
+
+// SurrealParam implements SurrealParam, binding the time as a datetime with
+// nanosecond precision.
+func (t DateTime) SurrealParam() (string, any) {
+	return "<datetime>$", t.String()
+}
+
+// NullDateTime is a DateTime that may be NULL, like sql.NullTime.
+type NullDateTime struct {
+	DateTime DateTime
+	Valid    bool // Valid is true if DateTime is not NULL
+}
+
+var _ json.Marshaler = (*NullDateTime)(nil)
+var _ json.Unmarshaler = (*NullDateTime)(nil)
+var _ driver.Valuer = (*NullDateTime)(nil)
+var _ sql.Scanner = (*NullDateTime)(nil)
+var _ SurrealMarshalable = (*NullDateTime)(nil)
+var _ SurrealParam = (*NullDateTime)(nil)
+
+// NewNullDateTime is t in UTC, not NULL.
+func NewNullDateTime(t time.Time) NullDateTime {
+	return NullDateTime{DateTime: NewDateTime(t), Valid: true}
+}
+
+func (t NullDateTime) String() string {
+	if !t.Valid {
+		return "NULL"
+	}
+	return t.DateTime.String()
+}
+
+func (t NullDateTime) MarshalJSON() ([]byte, error) {
+	if !t.Valid {
+		return []byte("null"), nil
+	}
+	return t.DateTime.MarshalJSON()
+}
+
+func (t *NullDateTime) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*t = NullDateTime{}
+		return nil
+	}
+	if err := t.DateTime.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	t.Valid = true
+	return nil
+}
+
+func (t NullDateTime) MarshalSurreal() ([]byte, error) {
+	if !t.Valid {
+		return []byte("NULL"), nil
+	}
+	return t.DateTime.MarshalSurreal()
+}
+
+func (t *NullDateTime) Scan(src any) error {
+	if src == nil {
+		*t = NullDateTime{}
+		return nil
+	}
+	if err := t.DateTime.Scan(src); err != nil {
+		return err
+	}
+	t.Valid = true
+	return nil
+}
+
+func (t NullDateTime) Value() (driver.Value, error) {
+	if !t.Valid {
+		return nil, nil
+	}
+	return t.DateTime.Value()
+}
+
+// SurrealParam implements SurrealParam; NULL is NULL.
+func (t NullDateTime) SurrealParam() (string, any) {
+	if !t.Valid {
+		return "$", nil
+	}
+	return t.DateTime.SurrealParam()
 }
@@ -0,0 +1,69 @@
+package surrealtypes_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
+)
+
+func TestDateTime(t *testing.T) {
+	want := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	for _, in := range []string{
+		"2024-01-02T03:04:05.123456789Z",
+		"2024-01-02T05:04:05.123456789+02:00",
+		"2024-01-02T03:04:05.123456789",
+		"d'2024-01-02T03:04:05.123456789Z'",
+	} {
+		d, err := st.ParseDateTime(in)
+		if err != nil || !d.Equal(want) || d.Location() != time.UTC {
+			t.Errorf("%s: got %s, %v", in, d, err)
+		}
+	}
+	if d, err := st.ParseDateTime("2024-01-02"); err != nil || d.String() != "2024-01-02T00:00:00Z" {
+		t.Errorf("date only: got %s, %v", d, err)
+	}
+	if d, err := st.ParseDateTime("yesterday"); err == nil {
+		t.Errorf("parsed %s", d)
+	}
+
+	at := st.NewDateTime(want.In(time.FixedZone("", -7*3600)))
+	b, err := json.Marshal(at)
+	if err != nil || string(b) != `"2024-01-02T03:04:05.123456789Z"` {
+		t.Errorf("marshalled %s: %v", b, err)
+	}
+	var back st.DateTime
+	if err := json.Unmarshal(b, &back); err != nil || back != at {
+		t.Errorf("came back as %s: %v", back, err)
+	}
+	if s, _ := at.MarshalSurreal(); string(s) != "d'2024-01-02T03:04:05.123456789Z'" {
+		t.Errorf("written as %s", s)
+	}
+
+	// Nanoseconds keep the order of events within a second.
+	first, second := st.NewDateTime(want), st.NewDateTime(want.Add(time.Nanosecond))
+	var a, c st.DateTime
+	if err := a.Scan([]byte(first.String())); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Scan(second.Time); err != nil || !a.Before(c.Time) {
+		t.Errorf("%s is not before %s: %v", a, c, err)
+	}
+
+	var row struct {
+		Deleted st.NullDateTime `json:"deleted"`
+	}
+	if err := json.Unmarshal([]byte(`{"deleted": null}`), &row); err != nil || row.Deleted.Valid {
+		t.Errorf("decoded %v: %v", row.Deleted, err)
+	}
+	if err := row.Deleted.Scan("2024-01-02"); err != nil || !row.Deleted.Valid {
+		t.Errorf("scanned %v: %v", row.Deleted, err)
+	}
+	if err := row.Deleted.Scan(nil); err != nil || row.Deleted.Valid {
+		t.Errorf("scanned %v: %v", row.Deleted, err)
+	}
+	if expr, v := row.Deleted.SurrealParam(); expr != "$" || v != nil {
+		t.Errorf("bound as %s, %v", expr, v)
+	}
+}
@@ -3,56 +3,376 @@ package surrealtypes
 import (
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
+	"strings"
 
 	"github.com/goccy/go-json"
 )
 
+// Decimal is an exact decimal number of any size: a coefficient times ten
+// to the power of minus the scale. 12.50 is 1250 with scale 2; the scale is
+// kept, so it is written as 12.50 again. The zero value is 0.
+//
+// Decimals are values: the methods return new decimals rather than
+// changing the receiver, and they may be copied freely. Rounding uses the
+// modes of math/big, i.e. big.ToNearestEven for banker's rounding or
+// big.ToNearestAway for the one taught in school.
+//
+// Scales lie within ±10000. ParseDecimal fails beyond that; constructors
+// and methods given or producing such a scale panic, as they would
+// otherwise build powers of ten with billions of digits.
 type Decimal struct {
-	*big.Float
+	coef  *big.Int // nil for zero; never changed once set
+	scale int32
 }
 
 var _ json.Marshaler = (*Decimal)(nil)
 var _ json.Unmarshaler = (*Decimal)(nil)
 var _ driver.Valuer = (*Decimal)(nil)
 var _ sql.Scanner = (*Decimal)(nil)
+var _ SurrealMarshalable = (*Decimal)(nil)
+var _ SurrealParam = (*Decimal)(nil)
 
-func (bf Decimal) MarshalJSON() ([]byte, error) {
-	if bf.Float == nil {
-		return []byte("0"), nil
+// NewDecimal is coef * 10^-scale, i.e. NewDecimal(1250, 2) is 12.50.
+func NewDecimal(coef int64, scale int32) Decimal {
+	return makeDecimal(big.NewInt(coef), int64(scale))
+}
+
+// DecimalFromBigInt is coef * 10^-scale. coef is copied.
+func DecimalFromBigInt(coef *big.Int, scale int32) Decimal {
+	return makeDecimal(new(big.Int).Set(coef), int64(scale))
+}
+
+// Takes ownership of coef. A negative scale is multiplied out, so that the
+// decimal is written the way it reads back: 1e2 is 100, scale 0.
+func makeDecimal(coef *big.Int, scale int64) Decimal {
+	s := checkScale(scale)
+	if coef.Sign() == 0 {
+		return Decimal{scale: max(s, 0)}
 	}
-	// Convert to string and then JSON encode it
-	return json.Marshal(bf.Float.Text('f', -1)) // 'f' format keeps it in decimal form, -1 means full precision
+	if s < 0 {
+		coef.Mul(coef, pow10(-scale))
+		s = 0
+	}
+	return Decimal{coef: coef, scale: s}
 }
-func (bf *Decimal) UnmarshalJSON(data []byte) error {
-	var str string
-	if err := json.Unmarshal(data, &str); err != nil {
-		return err
+
+// The largest scale, either way, a decimal has. Beyond it, merely comparing
+// or printing a number would build powers of ten with more digits than any
+// sane input has.
+const maxDecimalScale = 10000
+
+// Panics unless scale lies within ±maxDecimalScale; see Decimal.
+func checkScale(scale int64) int32 {
+	if scale > maxDecimalScale || scale < -maxDecimalScale {
+		panic("decimal: scale " + strconv.FormatInt(scale, 10) + " out of range")
+	}
+	return int32(scale)
+}
+
+// ParseDecimal reads a decimal like 12.50, -1e-3, 1.5E+10 or 12.50dec. The
+// scale is the number of digits after the point, less the exponent; it must
+// lie within ±10000.
+func ParseDecimal(s string) (Decimal, error) {
+	in := strings.TrimSuffix(strings.TrimSpace(s), "dec")
+	mantissa, exp := in, int64(0)
+	if i := strings.IndexAny(in, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(in[i+1:], 10, 32); err != nil {
+			return Decimal{}, fmt.Errorf("decimal %q: bad exponent", s)
+		}
+		mantissa = in[:i]
+	}
+	whole, frac, _ := strings.Cut(mantissa, ".")
+	digits := strings.TrimLeft(whole, "+-")
+	if len(whole)-len(digits) > 1 || !isDigits(digits+frac) || (frac == "" && strings.HasSuffix(mantissa, ".")) {
+		return Decimal{}, fmt.Errorf("decimal %q: malformed", s)
+	}
+	coef, ok := new(big.Int).SetString(whole+frac, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("decimal %q: malformed", s)
+	}
+	scale := int64(len(frac)) - exp
+	if scale > maxDecimalScale || scale < -maxDecimalScale {
+		return Decimal{}, fmt.Errorf("decimal %q: exponent out of range", s)
+	}
+	return makeDecimal(coef, scale), nil
+}
+
+// MustDecimal is ParseDecimal, panicking on error.
+func MustDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// DecimalFromFloat is the shortest decimal that rounds to f at f's
+// precision, i.e. 0.1 for float64(0.1) rather than its binary expansion.
+// Use DecimalFromRat(new(big.Rat).SetFloat64(...)) for the exact value.
+func DecimalFromFloat(f *big.Float) (Decimal, error) {
+	if f.IsInf() {
+		return Decimal{}, errors.New("decimal: infinity")
+	}
+	return ParseDecimal(f.Text('e', -1))
+}
+
+// DecimalFromRat is r rounded to scale digits after the point.
+func DecimalFromRat(r *big.Rat, scale int32, mode big.RoundingMode) Decimal {
+	checkScale(int64(scale))
+	num, den := new(big.Int).Set(r.Num()), new(big.Int).Set(r.Denom())
+	if scale >= 0 {
+		num.Mul(num, pow10(int64(scale)))
+	} else {
+		den.Mul(den, pow10(-int64(scale)))
+	}
+	return makeDecimal(roundQuo(num, den, mode), int64(scale))
+}
+
+// Coefficient is d without the point, i.e. 1250 for 12.50.
+func (d Decimal) Coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(d.coef)
+}
+
+// Scale is the number of digits after the point. It is never negative:
+// 12 * 10^2 is 1200 with scale 0.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Sign is -1, 0 or 1.
+func (d Decimal) Sign() int {
+	if d.coef == nil {
+		return 0
+	}
+	return d.coef.Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.coef == nil
+}
+
+func (d Decimal) Neg() Decimal {
+	if d.coef == nil {
+		return d
+	}
+	return Decimal{coef: new(big.Int).Neg(d.coef), scale: d.scale}
+}
+
+func (d Decimal) Abs() Decimal {
+	if d.Sign() >= 0 {
+		return d
+	}
+	return d.Neg()
+}
+
+// Add is d + e, with the larger of the two scales.
+func (d Decimal) Add(e Decimal) Decimal {
+	a, b, scale := align(d, e)
+	return makeDecimal(a.Add(a, b), int64(scale))
+}
+
+// Sub is d - e, with the larger of the two scales.
+func (d Decimal) Sub(e Decimal) Decimal {
+	a, b, scale := align(d, e)
+	return makeDecimal(a.Sub(a, b), int64(scale))
+}
+
+// Mul is d * e, with the sum of the two scales.
+func (d Decimal) Mul(e Decimal) Decimal {
+	return makeDecimal(new(big.Int).Mul(d.Coefficient(), e.Coefficient()), int64(d.scale)+int64(e.scale))
+}
+
+// Div is d / e, rounded to scale digits after the point. It panics if e is
+// zero, like division of big.Int does.
+func (d Decimal) Div(e Decimal, scale int32, mode big.RoundingMode) Decimal {
+	if e.coef == nil {
+		panic("decimal: division by zero")
 	}
-	// TODO: Set precision
-	f, _, err := big.ParseFloat(str, 10, 256, big.ToNearestEven)
+	checkScale(int64(scale))
+	// d / e = d.coef / e.coef * 10^(e.scale - d.scale), and the result is
+	// taken times 10^scale.
+	num, den := d.Coefficient(), new(big.Int).Set(e.coef)
+	if shift := int64(scale) - int64(d.scale) + int64(e.scale); shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	return makeDecimal(roundQuo(num, den, mode), int64(scale))
+}
+
+// Round is d with scale digits after the point: rounded if there were more,
+// padded with zeros if there were fewer.
+func (d Decimal) Round(scale int32, mode big.RoundingMode) Decimal {
+	checkScale(int64(scale))
+	if scale >= d.scale {
+		return makeDecimal(d.Coefficient().Mul(d.Coefficient(), pow10(int64(scale)-int64(d.scale))), int64(scale))
+	}
+	return makeDecimal(roundQuo(d.Coefficient(), pow10(int64(d.scale)-int64(scale)), mode), int64(scale))
+}
+
+// Cmp is -1, 0 or 1 as d is less than, equal to or greater than e. The
+// scale does not matter: 1.5 equals 1.50.
+func (d Decimal) Cmp(e Decimal) int {
+	a, b, _ := align(d, e)
+	return a.Cmp(b)
+}
+
+// Equal tells whether d and e are the same number, see Cmp.
+func (d Decimal) Equal(e Decimal) bool {
+	return d.Cmp(e) == 0
+}
+
+// Rat is d as a fraction, exactly.
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.Coefficient(), pow10(int64(d.scale)))
+}
+
+// BigFloat is d rounded to the nearest float of prec bits; with prec 0,
+// enough bits are used to tell it from its neighbours.
+func (d Decimal) BigFloat(prec uint) *big.Float {
+	return new(big.Float).SetPrec(prec).SetRat(d.Rat())
+}
+
+// Float64 is the nearest float64 to d, and whether it is d exactly.
+func (d Decimal) Float64() (float64, bool) {
+	return d.Rat().Float64()
+}
+
+// String writes d without an exponent, with scale digits after the point.
+func (d Decimal) String() string {
+	digits := d.Coefficient().String()
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	switch {
+	case d.scale == 0:
+		return sign + digits
+	case len(digits) <= int(d.scale):
+		digits = strings.Repeat("0", int(d.scale)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// MarshalJSON writes d as a JSON number, with all its digits. Decoders
+// reading numbers as float64 lose some of them, though; as a query
+// parameter, d is sent as a string and cast, see SurrealParam.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON reads a number or a string; null leaves d as it is.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseDecimal(s)
 	if err != nil {
 		return err
 	}
-	bf.Float = f
+	*d = parsed
 	return nil
 }
 
+// MarshalSurreal writes d as a decimal literal, i.e. 12.50dec.
+func (d Decimal) MarshalSurreal() ([]byte, error) {
+	return []byte(d.String() + "dec"), nil
+}
+
 func (d *Decimal) Scan(src any) error {
+	var parsed Decimal
+	var err error
 	switch data := src.(type) {
 	case string:
-		f, _, err := big.ParseFloat(data, 10, 256, big.ToNearestEven)
-		if err != nil {
-			return err
-		}
-		d.Float = f
-		return nil
+		parsed, err = ParseDecimal(data)
+	case []byte:
+		err = parsed.UnmarshalJSON(data)
+	case int64:
+		parsed = NewDecimal(data, 0)
+	case float64:
+		parsed, err = ParseDecimal(strconv.FormatFloat(data, 'e', -1, 64))
 	default:
-		return fmt.Errorf("input must be string, found %T", src)
+		return fmt.Errorf("input must be string, []byte, int64 or float64, found %T", src)
 	}
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value implements driver.Valuer; d is written as a string, which keeps
+// all of its digits.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
 }
 
-func (d *Decimal) Value() (driver.Value, error) {
-	return d.MarshalJSON()
+// SurrealParam implements SurrealParam, binding the number as a decimal
+// without going through float64.
+func (d Decimal) SurrealParam() (string, any) {
+	return "<decimal>$", d.String()
+}
+
+// Both coefficients at the larger of the two scales. The results are new.
+func align(d, e Decimal) (*big.Int, *big.Int, int32) {
+	a, b := d.Coefficient(), e.Coefficient()
+	switch {
+	case d.scale < e.scale:
+		a.Mul(a, pow10(int64(e.scale)-int64(d.scale)))
+		return a, b, e.scale
+	case d.scale > e.scale:
+		b.Mul(b, pow10(int64(d.scale)-int64(e.scale)))
+	}
+	return a, b, d.scale
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+// num / den, rounded to an integer as mode says. num is overwritten.
+func roundQuo(num, den *big.Int, mode big.RoundingMode) *big.Int {
+	q, r := num.QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	// The sign of the exact quotient; q may be 0.
+	sign := int64(r.Sign() * den.Sign())
+	up := false
+	switch mode {
+	case big.ToZero:
+	case big.AwayFromZero:
+		up = true
+	case big.ToNegativeInf:
+		up = sign < 0
+	case big.ToPositiveInf:
+		up = sign > 0
+	default:
+		// To nearest: compare the remainder to half the divisor.
+		switch r.Abs(r).Lsh(r, 1).Cmp(new(big.Int).Abs(den)) {
+		case 1:
+			up = true
+		case 0:
+			up = mode == big.ToNearestAway || q.Bit(0) == 1
+		}
+	}
+	if up {
+		q.Add(q, big.NewInt(sign))
+	}
+	return q
 }
@@ -0,0 +1,130 @@
+package surrealtypes_test
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
+)
+
+func TestDecimal(t *testing.T) {
+	for _, c := range []struct{ in, want string }{
+		{"12.50", "12.50"},
+		{"-0.001", "-0.001"},
+		{".5", "0.5"},
+		{"1.5E+3", "1500"},
+		{"-1e-3dec", "-0.001"},
+		{"12e2", "1200"},
+		{"0.00", "0.00"},
+		{"+7", "7"},
+		{"0e5", "0"},
+		{"1e-10000", "0." + strings.Repeat("0", 9999) + "1"},
+	} {
+		d, err := st.ParseDecimal(c.in)
+		if err != nil || d.String() != c.want {
+			t.Errorf("%s: got %s, %v", c.in, d, err)
+		}
+	}
+	// Written as 1200, 12e2 must read back with the same scale.
+	if d := st.MustDecimal("12e2"); d.Scale() != 0 || d.Coefficient().Int64() != 1200 {
+		t.Errorf("12e2 is %s with scale %d", d.Coefficient(), d.Scale())
+	}
+	for _, bad := range []string{"", ".", "1.", "--1", "1e", "1.2.3", "NaN", "Inf", "0x10", "1e2000000000", "1e-10001", "1e99999999999"} {
+		if d, err := st.ParseDecimal(bad); err == nil {
+			t.Errorf("%q parsed as %s", bad, d)
+		}
+	}
+
+	// Scales beyond ±10000 panic rather than build huge powers of ten.
+	big1 := st.MustDecimal("1e-10000")
+	for name, f := range map[string]func(){
+		"NewDecimal":        func() { st.NewDecimal(1, math.MinInt32) },
+		"DecimalFromBigInt": func() { st.DecimalFromBigInt(big.NewInt(1), -10001) },
+		"Mul":               func() { big1.Mul(big1) },
+		"Round":             func() { big1.Round(math.MaxInt32, big.ToNearestEven) },
+		"Div":               func() { big1.Div(big1, math.MinInt32, big.ToNearestEven) },
+		"DecimalFromRat":    func() { st.DecimalFromRat(big.NewRat(1, 3), math.MaxInt32, big.ToNearestEven) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s did not panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+
+	tenth := st.MustDecimal("0.1")
+	if sum := tenth.Add(st.MustDecimal("0.2")); !sum.Equal(st.MustDecimal("0.3")) || sum.String() != "0.3" {
+		t.Errorf("0.1 + 0.2 = %s", sum)
+	}
+	price := st.MustDecimal("19.99")
+	if total := price.Mul(st.NewDecimal(3, 0)).Sub(st.MustDecimal("0.97")); total.String() != "59.00" {
+		t.Errorf("total %s", total)
+	}
+	if share := st.NewDecimal(100, 0).Div(st.NewDecimal(3, 0), 2, big.ToNearestEven); share.String() != "33.33" {
+		t.Errorf("share %s", share)
+	}
+	if c := st.MustDecimal("1.5").Cmp(st.MustDecimal("1.50")); c != 0 {
+		t.Errorf("1.5 vs 1.50: %d", c)
+	}
+	if c := st.MustDecimal("-2").Cmp(st.MustDecimal("1.99")); c != -1 {
+		t.Errorf("-2 vs 1.99: %d", c)
+	}
+
+	for _, c := range []struct {
+		in   string
+		mode big.RoundingMode
+		want string
+	}{
+		{"2.345", big.ToNearestEven, "2.34"},
+		{"2.355", big.ToNearestEven, "2.36"},
+		{"2.345", big.ToNearestAway, "2.35"},
+		{"-2.345", big.ToNearestAway, "-2.35"},
+		{"2.341", big.AwayFromZero, "2.35"},
+		{"-2.349", big.ToZero, "-2.34"},
+		{"-2.341", big.ToNegativeInf, "-2.35"},
+		{"-2.349", big.ToPositiveInf, "-2.34"},
+		{"0.004", big.ToPositiveInf, "0.01"},
+		{"-0.004", big.ToNearestEven, "0.00"},
+		{"2.3", big.ToNearestEven, "2.30"},
+	} {
+		if got := st.MustDecimal(c.in).Round(2, c.mode); got.String() != c.want {
+			t.Errorf("%s rounded with %v: %s", c.in, c.mode, got)
+		}
+	}
+
+	if r := st.MustDecimal("-1.25").Rat(); r.Cmp(big.NewRat(-5, 4)) != 0 {
+		t.Errorf("rat %s", r)
+	}
+	if d := st.DecimalFromRat(big.NewRat(2, 3), 4, big.ToNearestEven); d.String() != "0.6667" {
+		t.Errorf("2/3 is %s", d)
+	}
+	if d, err := st.DecimalFromFloat(big.NewFloat(0.1)); err != nil || d.String() != "0.1" {
+		t.Errorf("float 0.1 is %s: %v", d, err)
+	}
+	if f, _ := st.MustDecimal("0.1").BigFloat(53).Float64(); f != 0.1 {
+		t.Errorf("0.1 is %v", f)
+	}
+
+	var row struct {
+		Amount st.Decimal `json:"amount"`
+	}
+	if err := json.Unmarshal([]byte(`{"amount": "12345678901234567890.123456789"}`), &row); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := json.Marshal(row); string(b) != `{"amount":12345678901234567890.123456789}` {
+		t.Errorf("marshalled %s", b)
+	}
+	if b, _ := row.Amount.MarshalSurreal(); string(b) != "12345678901234567890.123456789dec" {
+		t.Errorf("written as %s", b)
+	}
+	var scanned st.Decimal
+	if err := scanned.Scan(0.1); err != nil || scanned.String() != "0.1" {
+		t.Errorf("scanned %s: %v", scanned, err)
+	}
+}
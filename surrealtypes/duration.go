@@ -3,56 +3,254 @@ package surrealtypes
 import (
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/goccy/go-json"
 )
 
+// Duration is a SurrealDB duration: seconds and nanoseconds, never negative,
+// up to over 500 billion years. A time.Duration only reaches 292 years, so
+// conversions to it may fail; see TimeDuration. The zero value is 0ns.
 type Duration struct {
-	time.Duration
+	secs  uint64
+	nanos uint32 // Below one second
 }
 
+// ErrDurationRange is returned when a duration does not fit a time.Duration
+// or a Duration.
+var ErrDurationRange = errors.New("duration out of range")
+
 var _ json.Marshaler = (*Duration)(nil)
 var _ json.Unmarshaler = (*Duration)(nil)
 var _ driver.Valuer = (*Duration)(nil)
 var _ sql.Scanner = (*Duration)(nil)
+var _ SurrealMarshalable = (*Duration)(nil)
+var _ SurrealParam = (*Duration)(nil)
 
-func (d *Duration) UnmarshalJSON(b []byte) error {
-	str := string(b)
-	if str == "null" {
-		d.Duration = 0
-		return nil
+// NewDuration is secs seconds and nanos nanoseconds; nanos may exceed a
+// second.
+func NewDuration(secs uint64, nanos uint32) Duration {
+	return Duration{secs: secs + uint64(nanos/1e9), nanos: nanos % 1e9}
+}
+
+// DurationOf converts d; negative durations fail, SurrealDB has none.
+func DurationOf(d time.Duration) (Duration, error) {
+	if d < 0 {
+		return Duration{}, fmt.Errorf("duration %s is negative", d)
+	}
+	return durationOf(uint64(d)), nil
+}
+
+// A number of nanoseconds.
+func durationOf(ns uint64) Duration {
+	return Duration{secs: ns / 1e9, nanos: uint32(ns % 1e9)}
+}
+
+// ParseDuration reads a duration as SurrealQL writes it: numbers with the
+// units ns, us (or µs), ms, s, m, h, d, w and y (365 days), i.e. 1y2w3d or
+// 1h30m. The parts may come in any order and add up.
+func ParseDuration(s string) (Duration, error) {
+	in := strings.TrimSpace(s)
+	if in == "" {
+		return Duration{}, errors.New("duration: empty")
 	}
+	var secs, nanos uint64
+	for in != "" {
+		end := 0
+		for end < len(in) && in[end] >= '0' && in[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			return Duration{}, fmt.Errorf("duration %q: expected a number at %q", s, in)
+		}
+		n, err := strconv.ParseUint(in[:end], 10, 64)
+		if err != nil {
+			return Duration{}, fmt.Errorf("duration %q: %w", s, ErrDurationRange)
+		}
+		in = in[end:]
+		unit, ok := durationUnit(in)
+		if !ok {
+			return Duration{}, fmt.Errorf("duration %q: unknown unit at %q", s, in)
+		}
+		in = in[len(unit.name):]
 
-	str = str[1 : len(str)-1]
+		var carry uint64
+		if unit.secs > 0 {
+			hi, part := bits.Mul64(n, unit.secs)
+			secs, carry = bits.Add64(secs, part, 0)
+			carry |= hi
+		} else {
+			perSec := uint64(1e9) / unit.nanos
+			secs, carry = bits.Add64(secs, n/perSec, 0)
+			nanos += n % perSec * unit.nanos
+		}
+		if carry != 0 {
+			return Duration{}, fmt.Errorf("duration %q: %w", s, ErrDurationRange)
+		}
+		if nanos >= 1e9 {
+			if secs, carry = bits.Add64(secs, nanos/1e9, 0); carry != 0 {
+				return Duration{}, fmt.Errorf("duration %q: %w", s, ErrDurationRange)
+			}
+			nanos %= 1e9
+		}
+	}
+	return Duration{secs: secs, nanos: uint32(nanos)}, nil
+}
+
+// Secs is the number of whole seconds.
+func (d Duration) Secs() uint64 {
+	return d.secs
+}
+
+// Nanos is the number of nanoseconds past the whole seconds.
+func (d Duration) Nanos() uint32 {
+	return d.nanos
+}
+
+func (d Duration) IsZero() bool {
+	return d.secs == 0 && d.nanos == 0
+}
+
+// TimeDuration converts d, failing with ErrDurationRange past 292 years.
+func (d Duration) TimeDuration() (time.Duration, error) {
+	const limit = uint64(math.MaxInt64)
+	if d.secs > limit/1e9 || (d.secs == limit/1e9 && uint64(d.nanos) > limit%1e9) {
+		return 0, fmt.Errorf("%s: %w", d, ErrDurationRange)
+	}
+	return time.Duration(d.secs)*time.Second + time.Duration(d.nanos), nil
+}
+
+// String writes d as SurrealDB does, largest unit first, i.e. 1y2w3d or
+// 1h30m500ms; zero is 0ns.
+func (d Duration) String() string {
+	if d.IsZero() {
+		return "0ns"
+	}
+	out := strings.Builder{}
+	secs, nanos := d.secs, uint64(d.nanos)
+	for _, unit := range durationUnits {
+		var n uint64
+		if unit.secs > 0 {
+			n, secs = secs/unit.secs, secs%unit.secs
+		} else {
+			n, nanos = nanos/unit.nanos, nanos%unit.nanos
+		}
+		if n > 0 {
+			out.WriteString(strconv.FormatUint(n, 10))
+			out.WriteString(unit.name)
+		}
+	}
+	return out.String()
+}
 
-	duration, err := time.ParseDuration(str)
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*d = Duration{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseDuration(s)
 	if err != nil {
 		return err
 	}
-	d.Duration = duration
+	*d = parsed
 	return nil
 }
 
-func (d *Duration) MarshalJSON() ([]byte, error) {
-	return json.MarshalNoEscape(d.Duration.String())
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.MarshalNoEscape(d.String())
+}
+
+// MarshalSurreal writes d as a duration literal, i.e. 1h30m.
+func (d Duration) MarshalSurreal() ([]byte, error) {
+	return []byte(d.String()), nil
 }
 
+// Scan reads a duration written by SurrealDB, as a string or as JSON, or
+// a time.Duration or a number of nanoseconds.
 func (d *Duration) Scan(src any) error {
+	var parsed Duration
+	var err error
 	switch data := src.(type) {
 	case string:
-		tmp, err := time.ParseDuration(data)
-		if err != nil {
-			return err
+		parsed, err = ParseDuration(data)
+	case []byte:
+		if strings.HasPrefix(string(data), `"`) {
+			err = parsed.UnmarshalJSON(data)
+		} else {
+			parsed, err = ParseDuration(string(data))
 		}
-		d.Duration = tmp
-		return nil
+	case time.Duration:
+		parsed, err = DurationOf(data)
+	case int64:
+		parsed, err = DurationOf(time.Duration(data))
 	default:
-		return fmt.Errorf("input must be string, found %T", src)
+		return fmt.Errorf("input must be string, []byte, time.Duration or int64, found %T", src)
+	}
+	if err != nil {
+		return err
 	}
+	*d = parsed
+	return nil
+}
+
+func (d Duration) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// SurrealParam implements SurrealParam, binding the duration as a duration.
+func (d Duration) SurrealParam() (string, any) {
+	return "<duration>$", d.String()
+}
+
+// Binds a time.Duration like a Duration. SurrealDB has no negative
+// durations; those are sent with a '-', for SurrealDB to say so.
+func durationParam(d time.Duration) (string, any) {
+	if d < 0 {
+		// -d overflows for the smallest duration, but as uint64 it is right.
+		return "<duration>$", "-" + durationOf(uint64(-d)).String()
+	}
+	return durationOf(uint64(d)).SurrealParam()
 }
 
-func (d *Duration) Value() (driver.Value, error) {
-	return d.MarshalJSON()
+type durationUnitSize struct {
+	name  string
+	secs  uint64 // For units of seconds or more
+	nanos uint64 // For units below a second
+}
+
+// Units of a duration, largest first, as SurrealDB writes them.
+var durationUnits = []durationUnitSize{
+	{name: "y", secs: 365 * 24 * 60 * 60},
+	{name: "w", secs: 7 * 24 * 60 * 60},
+	{name: "d", secs: 24 * 60 * 60},
+	{name: "h", secs: 60 * 60},
+	{name: "m", secs: 60},
+	{name: "s", secs: 1},
+	{name: "ms", nanos: 1e6},
+	{name: "µs", nanos: 1e3},
+	{name: "ns", nanos: 1},
+}
+
+// The unit s starts with; ms rather than m.
+func durationUnit(s string) (durationUnitSize, bool) {
+	if strings.HasPrefix(s, "us") {
+		return durationUnitSize{name: "us", nanos: 1e3}, true
+	}
+	var best durationUnitSize
+	for _, unit := range durationUnits {
+		if strings.HasPrefix(s, unit.name) && len(unit.name) > len(best.name) {
+			best = unit
+		}
+	}
+	return best, best.name != ""
 }
@@ -0,0 +1,71 @@
+package surrealtypes_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
+)
+
+func TestDuration(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want string
+		secs uint64
+	}{
+		{"1y2w3d", "1y2w3d", 365*86400 + 14*86400 + 3*86400},
+		{"1h30m", "1h30m", 5400},
+		{"90m", "1h30m", 5400},
+		{"1500ms", "1s500ms", 1},
+		{"3us", "3µs", 0},
+		{"1s1h", "1h1s", 3601},
+		{"0s", "0ns", 0},
+		{"600000y", "600000y", 600000 * 365 * 86400},
+	} {
+		d, err := st.ParseDuration(c.in)
+		if err != nil || d.String() != c.want || d.Secs() != c.secs {
+			t.Errorf("%s: got %s (%ds), %v", c.in, d, d.Secs(), err)
+			continue
+		}
+		if again, err := st.ParseDuration(d.String()); err != nil || again != d {
+			t.Errorf("%s came back as %v: %v", d, again, err)
+		}
+	}
+	for _, bad := range []string{"", "1", "h", "1.5s", "-1s", "1x", "1h 2m", "99999999999999999999ns", "18446744073709551615y"} {
+		if d, err := st.ParseDuration(bad); err == nil {
+			t.Errorf("%q parsed as %s", bad, d)
+		}
+	}
+
+	long, _ := st.ParseDuration("300y")
+	if _, err := long.TimeDuration(); !errors.Is(err, st.ErrDurationRange) {
+		t.Errorf("300y converted: %v", err)
+	}
+	if d, err := st.ParseDuration("292y"); err != nil {
+		t.Fatal(err)
+	} else if td, err := d.TimeDuration(); err != nil || td != 292*365*24*time.Hour {
+		t.Errorf("292y is %v: %v", td, err)
+	}
+	if _, err := st.DurationOf(-time.Second); err == nil {
+		t.Error("a negative duration was converted")
+	}
+
+	var retention struct {
+		Keep st.Duration `json:"keep"`
+	}
+	if err := json.Unmarshal([]byte(`{"keep": "1y6w"}`), &retention); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := json.Marshal(retention); string(b) != `{"keep":"1y6w"}` {
+		t.Errorf("marshalled %s", b)
+	}
+	var scanned st.Duration
+	if err := scanned.Scan("2w"); err != nil || scanned.Secs() != 14*86400 {
+		t.Errorf("scanned %s: %v", scanned, err)
+	}
+	if err := scanned.Scan(90 * time.Minute); err != nil || scanned.String() != "1h30m" {
+		t.Errorf("scanned %s: %v", scanned, err)
+	}
+}
@@ -0,0 +1,575 @@
+package surrealtypes
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+	geojson "github.com/paulmach/go.geojson"
+)
+
+// GeometryValue is one of SurrealDB's geometries: Point, LineString,
+// Polygon, MultiPoint, MultiLineString, MultiPolygon or GeometryCollection.
+//
+// They are sent and received as GeoJSON, which SurrealDB turns into
+// geometries by itself; a point may also be read from SurrealDB's (lon, lat)
+// shorthand, and MarshalSurreal writes it that way. Geometries are validated
+// both ways: coordinates must be finite, lines need two points and polygon
+// rings four, the last one repeating the first. A nil geometry is NULL.
+type GeometryValue interface {
+	GeoJSON() *geojson.Geometry
+	Validate() error
+}
+
+var _ GeometryValue = Point{}
+var _ GeometryValue = LineString{}
+var _ GeometryValue = Polygon{}
+var _ GeometryValue = MultiPoint{}
+var _ GeometryValue = MultiLineString{}
+var _ GeometryValue = MultiPolygon{}
+var _ GeometryValue = GeometryCollection{}
+
+// Point is a longitude and a latitude, in that order.
+type Point [2]float64
+
+// LineString is a line through two or more points.
+type LineString []Point
+
+// Polygon is an exterior ring followed by the rings of its holes. A ring is
+// closed: its last point is its first one.
+type Polygon []LineString
+
+type MultiPoint []Point
+type MultiLineString []LineString
+type MultiPolygon []Polygon
+type GeometryCollection []GeometryValue
+
+var _ json.Marshaler = (*Point)(nil)
+var _ json.Unmarshaler = (*Point)(nil)
+var _ driver.Valuer = (*Point)(nil)
+var _ sql.Scanner = (*Point)(nil)
+var _ SurrealMarshalable = (*Point)(nil)
+
+var _ json.Marshaler = (*LineString)(nil)
+var _ json.Unmarshaler = (*LineString)(nil)
+var _ driver.Valuer = (*LineString)(nil)
+var _ sql.Scanner = (*LineString)(nil)
+var _ SurrealMarshalable = (*LineString)(nil)
+
+var _ json.Marshaler = (*Polygon)(nil)
+var _ json.Unmarshaler = (*Polygon)(nil)
+var _ driver.Valuer = (*Polygon)(nil)
+var _ sql.Scanner = (*Polygon)(nil)
+var _ SurrealMarshalable = (*Polygon)(nil)
+
+var _ json.Marshaler = (*MultiPoint)(nil)
+var _ json.Unmarshaler = (*MultiPoint)(nil)
+var _ driver.Valuer = (*MultiPoint)(nil)
+var _ sql.Scanner = (*MultiPoint)(nil)
+var _ SurrealMarshalable = (*MultiPoint)(nil)
+
+var _ json.Marshaler = (*MultiLineString)(nil)
+var _ json.Unmarshaler = (*MultiLineString)(nil)
+var _ driver.Valuer = (*MultiLineString)(nil)
+var _ sql.Scanner = (*MultiLineString)(nil)
+var _ SurrealMarshalable = (*MultiLineString)(nil)
+
+var _ json.Marshaler = (*MultiPolygon)(nil)
+var _ json.Unmarshaler = (*MultiPolygon)(nil)
+var _ driver.Valuer = (*MultiPolygon)(nil)
+var _ sql.Scanner = (*MultiPolygon)(nil)
+var _ SurrealMarshalable = (*MultiPolygon)(nil)
+
+var _ json.Marshaler = (*GeometryCollection)(nil)
+var _ json.Unmarshaler = (*GeometryCollection)(nil)
+var _ driver.Valuer = (*GeometryCollection)(nil)
+var _ sql.Scanner = (*GeometryCollection)(nil)
+var _ SurrealMarshalable = (*GeometryCollection)(nil)
+
+// NewPoint is the point at lon, lat.
+func NewPoint(lon, lat float64) Point {
+	return Point{lon, lat}
+}
+
+func (p Point) Lon() float64 {
+	return p[0]
+}
+
+func (p Point) Lat() float64 {
+	return p[1]
+}
+
+// String writes p in SurrealDB's shorthand, i.e. (-0.118092, 51.509865).
+func (p Point) String() string {
+	return "(" + formatFloat(p[0]) + ", " + formatFloat(p[1]) + ")"
+}
+
+func (p Point) GeoJSON() *geojson.Geometry {
+	return geojson.NewPointGeometry(p.coords())
+}
+
+func (p Point) Validate() error {
+	if math.IsNaN(p[0]) || math.IsInf(p[0], 0) || math.IsNaN(p[1]) || math.IsInf(p[1], 0) {
+		return fmt.Errorf("geometry: point %v is not finite", [2]float64(p))
+	}
+	return nil
+}
+
+func (p Point) MarshalJSON() ([]byte, error) {
+	return marshalGeometry(p)
+}
+func (p *Point) UnmarshalJSON(b []byte) error {
+	return unmarshalGeometry(b, p)
+}
+func (p *Point) Scan(src any) error {
+	return scanGeometry(src, p)
+}
+func (p Point) Value() (driver.Value, error) {
+	return geometryValue(p)
+}
+
+// MarshalSurreal writes the point as (lon, lat).
+func (p Point) MarshalSurreal() ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return []byte(p.String()), nil
+}
+
+func (l LineString) GeoJSON() *geojson.Geometry {
+	return geojson.NewLineStringGeometry(coordsOf(l))
+}
+
+func (l LineString) Validate() error {
+	if len(l) < 2 {
+		return fmt.Errorf("geometry: a line needs at least 2 points, not %d", len(l))
+	}
+	return validateAll(l)
+}
+
+func (l LineString) MarshalJSON() ([]byte, error) {
+	return marshalGeometry(l)
+}
+func (l *LineString) UnmarshalJSON(b []byte) error {
+	return unmarshalGeometry(b, l)
+}
+func (l *LineString) Scan(src any) error {
+	return scanGeometry(src, l)
+}
+func (l LineString) Value() (driver.Value, error) {
+	return geometryValue(l)
+}
+
+func (l LineString) MarshalSurreal() ([]byte, error) {
+	return l.MarshalJSON()
+}
+
+func (pg Polygon) GeoJSON() *geojson.Geometry {
+	return geojson.NewPolygonGeometry(pg.coords())
+}
+
+func (pg Polygon) Validate() error {
+	if len(pg) == 0 {
+		return errors.New("geometry: a polygon needs an exterior ring")
+	}
+	for i, ring := range pg {
+		if len(ring) < 4 {
+			return fmt.Errorf("geometry: ring %d has %d points, not at least 4", i, len(ring))
+		}
+		if ring[0] != ring[len(ring)-1] {
+			return fmt.Errorf("geometry: ring %d is not closed, it ends at %v instead of %v", i, ring[len(ring)-1], ring[0])
+		}
+		if err := validateAll(ring); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pg Polygon) MarshalJSON() ([]byte, error) {
+	return marshalGeometry(pg)
+}
+func (pg *Polygon) UnmarshalJSON(b []byte) error {
+	return unmarshalGeometry(b, pg)
+}
+func (pg *Polygon) Scan(src any) error {
+	return scanGeometry(src, pg)
+}
+func (pg Polygon) Value() (driver.Value, error) {
+	return geometryValue(pg)
+}
+
+func (pg Polygon) MarshalSurreal() ([]byte, error) {
+	return pg.MarshalJSON()
+}
+
+func (mp MultiPoint) GeoJSON() *geojson.Geometry {
+	return geojson.NewMultiPointGeometry(coordsOf(mp)...)
+}
+
+func (mp MultiPoint) Validate() error {
+	return validateAll(mp)
+}
+
+func (mp MultiPoint) MarshalJSON() ([]byte, error) {
+	return marshalGeometry(mp)
+}
+func (mp *MultiPoint) UnmarshalJSON(b []byte) error {
+	return unmarshalGeometry(b, mp)
+}
+func (mp *MultiPoint) Scan(src any) error {
+	return scanGeometry(src, mp)
+}
+func (mp MultiPoint) Value() (driver.Value, error) {
+	return geometryValue(mp)
+}
+
+func (mp MultiPoint) MarshalSurreal() ([]byte, error) {
+	return mp.MarshalJSON()
+}
+
+func (ml MultiLineString) GeoJSON() *geojson.Geometry {
+	lines := make([][][]float64, len(ml))
+	for i, l := range ml {
+		lines[i] = coordsOf(l)
+	}
+	return geojson.NewMultiLineStringGeometry(lines...)
+}
+
+func (ml MultiLineString) Validate() error {
+	return validateAll(ml)
+}
+
+func (ml MultiLineString) MarshalJSON() ([]byte, error) {
+	return marshalGeometry(ml)
+}
+func (ml *MultiLineString) UnmarshalJSON(b []byte) error {
+	return unmarshalGeometry(b, ml)
+}
+func (ml *MultiLineString) Scan(src any) error {
+	return scanGeometry(src, ml)
+}
+func (ml MultiLineString) Value() (driver.Value, error) {
+	return geometryValue(ml)
+}
+
+func (ml MultiLineString) MarshalSurreal() ([]byte, error) {
+	return ml.MarshalJSON()
+}
+
+func (mp MultiPolygon) GeoJSON() *geojson.Geometry {
+	polygons := make([][][][]float64, len(mp))
+	for i, pg := range mp {
+		polygons[i] = pg.coords()
+	}
+	return geojson.NewMultiPolygonGeometry(polygons...)
+}
+
+func (mp MultiPolygon) Validate() error {
+	return validateAll(mp)
+}
+
+func (mp MultiPolygon) MarshalJSON() ([]byte, error) {
+	return marshalGeometry(mp)
+}
+func (mp *MultiPolygon) UnmarshalJSON(b []byte) error {
+	return unmarshalGeometry(b, mp)
+}
+func (mp *MultiPolygon) Scan(src any) error {
+	return scanGeometry(src, mp)
+}
+func (mp MultiPolygon) Value() (driver.Value, error) {
+	return geometryValue(mp)
+}
+
+func (mp MultiPolygon) MarshalSurreal() ([]byte, error) {
+	return mp.MarshalJSON()
+}
+
+func (gc GeometryCollection) GeoJSON() *geojson.Geometry {
+	geometries := make([]*geojson.Geometry, len(gc))
+	for i, g := range gc {
+		if g != nil {
+			geometries[i] = g.GeoJSON()
+		}
+	}
+	return geojson.NewCollectionGeometry(geometries...)
+}
+
+func (gc GeometryCollection) Validate() error {
+	for i, g := range gc {
+		if g == nil {
+			return fmt.Errorf("geometry: collection member %d is nil", i)
+		}
+		if err := g.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (gc GeometryCollection) MarshalJSON() ([]byte, error) {
+	return marshalGeometry(gc)
+}
+func (gc *GeometryCollection) UnmarshalJSON(b []byte) error {
+	return unmarshalGeometry(b, gc)
+}
+func (gc *GeometryCollection) Scan(src any) error {
+	return scanGeometry(src, gc)
+}
+func (gc GeometryCollection) Value() (driver.Value, error) {
+	return geometryValue(gc)
+}
+
+func (gc GeometryCollection) MarshalSurreal() ([]byte, error) {
+	return gc.MarshalJSON()
+}
+
+// FromGeoJSON converts a GeoJSON geometry to the matching GeometryValue, and
+// validates it.
+func FromGeoJSON(g *geojson.Geometry) (GeometryValue, error) {
+	if g == nil {
+		return nil, errors.New("geometry: nil")
+	}
+	var out GeometryValue
+	var err error
+	switch g.Type {
+	case geojson.GeometryPoint:
+		out, err = toPoint(g.Point)
+	case geojson.GeometryLineString:
+		out, err = toPoints[LineString](g.LineString)
+	case geojson.GeometryPolygon:
+		out, err = toPolygon(g.Polygon)
+	case geojson.GeometryMultiPoint:
+		out, err = toPoints[MultiPoint](g.MultiPoint)
+	case geojson.GeometryMultiLineString:
+		lines := make(MultiLineString, len(g.MultiLineString))
+		for i, l := range g.MultiLineString {
+			if lines[i], err = toPoints[LineString](l); err != nil {
+				return nil, err
+			}
+		}
+		out = lines
+	case geojson.GeometryMultiPolygon:
+		polygons := make(MultiPolygon, len(g.MultiPolygon))
+		for i, pg := range g.MultiPolygon {
+			if polygons[i], err = toPolygon(pg); err != nil {
+				return nil, err
+			}
+		}
+		out = polygons
+	case geojson.GeometryCollection:
+		gc := make(GeometryCollection, len(g.Geometries))
+		for i, member := range g.Geometries {
+			if gc[i], err = FromGeoJSON(member); err != nil {
+				return nil, err
+			}
+		}
+		out = gc
+	default:
+		return nil, fmt.Errorf("geometry: unknown type %q", g.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := out.Validate(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ParseGeometry reads a geometry as GeoJSON, a JSON [lon, lat] pair or in
+// SurrealDB's (lon, lat) point shorthand, also when given as a JSON string.
+func ParseGeometry(b []byte) (GeometryValue, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil, errors.New("geometry: empty")
+	}
+	switch b[0] {
+	case '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(strings.TrimSpace(s), `"`) {
+			return nil, errors.New("geometry: quoted twice")
+		}
+		return ParseGeometry([]byte(s))
+	case '(':
+		return parsePoint(string(b))
+	case '[':
+		var coords []float64
+		if err := json.Unmarshal(b, &coords); err != nil {
+			return nil, fmt.Errorf("geometry: %w", err)
+		}
+		p, err := toPoint(coords)
+		if err != nil {
+			return nil, err
+		}
+		return p, p.Validate()
+	}
+	g, err := geojson.UnmarshalGeometry(b)
+	if err != nil {
+		return nil, fmt.Errorf("geometry: %w", err)
+	}
+	return FromGeoJSON(g)
+}
+
+// A point written as (lon, lat).
+func parsePoint(s string) (Point, error) {
+	inner, ok := strings.CutPrefix(strings.TrimSpace(s), "(")
+	if inner, ok = strings.CutSuffix(inner, ")"); !ok {
+		return Point{}, fmt.Errorf("geometry: %q is not a point", s)
+	}
+	lon, lat, ok := strings.Cut(inner, ",")
+	if !ok {
+		return Point{}, fmt.Errorf("geometry: %q is not a point", s)
+	}
+	var p Point
+	var err error
+	if p[0], err = strconv.ParseFloat(strings.TrimSpace(lon), 64); err != nil {
+		return Point{}, fmt.Errorf("geometry: longitude of %q: %w", s, err)
+	}
+	if p[1], err = strconv.ParseFloat(strings.TrimSpace(lat), 64); err != nil {
+		return Point{}, fmt.Errorf("geometry: latitude of %q: %w", s, err)
+	}
+	return p, p.Validate()
+}
+
+// The SurrealQL type of g, i.e. geometry<polygon>.
+func geometryKind(g GeometryValue) string {
+	kind, ok := map[geojson.GeometryType]string{
+		geojson.GeometryPoint:           "point",
+		geojson.GeometryLineString:      "line",
+		geojson.GeometryPolygon:         "polygon",
+		geojson.GeometryMultiPoint:      "multipoint",
+		geojson.GeometryMultiLineString: "multiline",
+		geojson.GeometryMultiPolygon:    "multipolygon",
+		geojson.GeometryCollection:      "collection",
+	}[g.GeoJSON().Type]
+	if !ok {
+		return "geometry"
+	}
+	return "geometry<" + kind + ">"
+}
+
+func marshalGeometry(g GeometryValue) ([]byte, error) {
+	if isNilGeometry(g) {
+		return []byte("null"), nil
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(g.GeoJSON())
+}
+
+func unmarshalGeometry[G GeometryValue](b []byte, out *G) error {
+	if string(bytes.TrimSpace(b)) == "null" {
+		var zero G
+		*out = zero
+		return nil
+	}
+	g, err := ParseGeometry(b)
+	if err != nil {
+		return err
+	}
+	v, ok := g.(G)
+	if !ok {
+		return fmt.Errorf("geometry: got a %s, not a %s", g.GeoJSON().Type, (*out).GeoJSON().Type)
+	}
+	*out = v
+	return nil
+}
+
+func scanGeometry[G GeometryValue](src any, out *G) error {
+	switch data := src.(type) {
+	case nil:
+		var zero G
+		*out = zero
+		return nil
+	case []byte:
+		return unmarshalGeometry(data, out)
+	case string:
+		return unmarshalGeometry([]byte(data), out)
+	default:
+		return fmt.Errorf("input must be []byte or string, found %T", src)
+	}
+}
+
+func geometryValue(g GeometryValue) (driver.Value, error) {
+	if isNilGeometry(g) {
+		return nil, nil
+	}
+	return marshalGeometry(g)
+}
+
+func isNilGeometry(g GeometryValue) bool {
+	v := reflect.ValueOf(g)
+	return !v.IsValid() || (v.Kind() == reflect.Slice && v.IsNil())
+}
+
+func validateAll[G GeometryValue](gs []G) error {
+	for _, g := range gs {
+		if err := g.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p Point) coords() []float64 {
+	return []float64{p[0], p[1]}
+}
+
+func coordsOf[L ~[]Point](points L) [][]float64 {
+	out := make([][]float64, len(points))
+	for i, p := range points {
+		out[i] = p.coords()
+	}
+	return out
+}
+
+func (pg Polygon) coords() [][][]float64 {
+	out := make([][][]float64, len(pg))
+	for i, ring := range pg {
+		out[i] = coordsOf(ring)
+	}
+	return out
+}
+
+func toPoint(coords []float64) (Point, error) {
+	if len(coords) != 2 {
+		return Point{}, fmt.Errorf("geometry: a point has 2 coordinates, not %d", len(coords))
+	}
+	return Point{coords[0], coords[1]}, nil
+}
+
+func toPoints[L ~[]Point](coords [][]float64) (L, error) {
+	out := make(L, len(coords))
+	for i, c := range coords {
+		p, err := toPoint(c)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = p
+	}
+	return out, nil
+}
+
+func toPolygon(rings [][][]float64) (Polygon, error) {
+	out := make(Polygon, len(rings))
+	for i, ring := range rings {
+		var err error
+		if out[i], err = toPoints[LineString](ring); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,70 @@
+package surrealtypes_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
+)
+
+func TestGeometry(t *testing.T) {
+	area := st.Polygon{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{2, 2}, {3, 2}, {3, 3}, {2, 2}},
+	}
+	b, err := json.Marshal(area)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back st.Polygon
+	if err := json.Unmarshal(b, &back); err != nil || !reflect.DeepEqual(back, area) {
+		t.Errorf("%s came back as %v: %v", b, back, err)
+	}
+	if err := back.Scan(`{"type": "Point", "coordinates": [1, 2]}`); err == nil {
+		t.Error("a point was scanned into a polygon")
+	}
+
+	open := st.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}}
+	if _, err := json.Marshal(open); err == nil {
+		t.Error("an open ring was marshalled")
+	}
+	if err := json.Unmarshal([]byte(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [0, 0]]]}`), &back); err == nil {
+		t.Error("a ring of 3 points was unmarshalled")
+	}
+
+	var p st.Point
+	for _, in := range []any{"(-0.118092, 51.509865)", []byte(`[-0.118092, 51.509865]`), []byte(`{"type": "Point", "coordinates": [-0.118092, 51.509865]}`)} {
+		if err := p.Scan(in); err != nil || p != st.NewPoint(-0.118092, 51.509865) {
+			t.Errorf("scanned %v from %s: %v", p, in, err)
+		}
+	}
+	if s, _ := st.NewPoint(1, 2.5).MarshalSurreal(); string(s) != "(1.0, 2.5)" {
+		t.Errorf("point written as %s", s)
+	}
+
+	var gc st.GeometryCollection
+	if err := gc.Scan([]byte(`{"type": "GeometryCollection", "geometries": [
+		{"type": "Point", "coordinates": [1, 2]},
+		{"type": "LineString", "coordinates": [[1, 2], [3, 4]]}
+	]}`)); err != nil {
+		t.Fatal(err)
+	}
+	want := st.GeometryCollection{st.NewPoint(1, 2), st.LineString{{1, 2}, {3, 4}}}
+	if !reflect.DeepEqual(gc, want) {
+		t.Errorf("collection %#v", gc)
+	}
+
+	var trip struct {
+		Area st.MultiPolygon `json:"area"`
+	}
+	if b, err := json.Marshal(trip); err != nil || string(b) != `{"area":null}` {
+		t.Errorf("no area marshalled as %s: %v", b, err)
+	}
+	if kind := st.Some(area).GormDataType(); kind != "option<geometry<polygon>>" {
+		t.Errorf("kind %s", kind)
+	}
+	if kind := st.Some[*st.Point](nil).GormDataType(); kind != "option<geometry<point>>" {
+		t.Errorf("kind %s", kind)
+	}
+}
@@ -8,8 +8,6 @@ import (
 	"github.com/goccy/go-json"
 )
 
-var objectKemba = localKemba.Extend("Object")
-
 type Object map[string]interface{}
 
 var _ json.Marshaler = (*Object)(nil)
@@ -36,8 +34,6 @@ func (o *Object) Value() (driver.Value, error) {
 }
 
 func (o *Object) Scan(value interface{}) error {
-	k := objectKemba.Extend("Scan")
-	k.Printf("Deserializing: %s", value)
 	var err error
 	if b, ok := value.([]byte); ok {
 		err = o.UnmarshalJSON(b)
@@ -0,0 +1,258 @@
+package surrealtypes
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// None is SurrealDB's NONE: no value at all, as opposed to NULL. As a query
+// parameter, it removes the field it is assigned to:
+//
+//	db.Exec("UPDATE person:tobie SET nickname = $_1", st.None)
+var None = none{}
+
+type none struct{}
+
+var _ SurrealParam = None
+var _ SurrealMarshalable = None
+
+func (none) IsNone() bool {
+	return true
+}
+
+func (none) String() string {
+	return "NONE"
+}
+
+// SurrealParam implements SurrealParam; the parameter is replaced by NONE.
+func (none) SurrealParam() (string, any) {
+	return "NONE", nil
+}
+
+func (none) MarshalSurreal() ([]byte, error) {
+	return []byte("NONE"), nil
+}
+
+// Option is a value of an option<T> field, which may be absent (NONE), NULL
+// or hold a T. The zero value is NONE.
+//
+// In a struct decoded from JSON, a missing field stays NONE and null becomes
+// NULL. database/sql can not tell the two apart, though: scanning a column
+// with no value gives NULL. Decode the record as JSON where the difference
+// matters.
+//
+// As a query parameter, NONE becomes NONE, removing the field it is assigned
+// to. Marshalled to JSON, NONE can only be written as null; leave the field
+// out of the object instead.
+type Option[T any] struct {
+	value T
+	state optionState
+}
+
+type optionState uint8
+
+const (
+	optionNone optionState = iota
+	optionNull
+	optionSome
+)
+
+var _ json.Marshaler = (*Option[int])(nil)
+var _ json.Unmarshaler = (*Option[int])(nil)
+var _ driver.Valuer = (*Option[int])(nil)
+var _ sql.Scanner = (*Option[int])(nil)
+var _ SurrealParam = (*Option[int])(nil)
+
+// Some is an Option holding v.
+func Some[T any](v T) Option[T] {
+	return Option[T]{value: v, state: optionSome}
+}
+
+// Null is an Option that is NULL.
+func Null[T any]() Option[T] {
+	return Option[T]{state: optionNull}
+}
+
+func (o Option[T]) IsNone() bool {
+	return o.state == optionNone
+}
+
+func (o Option[T]) IsNull() bool {
+	return o.state == optionNull
+}
+
+// Get returns the value, if there is one.
+func (o Option[T]) Get() (T, bool) {
+	return o.value, o.state == optionSome
+}
+
+func (o Option[T]) String() string {
+	switch o.state {
+	case optionNull:
+		return "NULL"
+	case optionSome:
+		b, err := json.Marshal(o.value)
+		if err == nil {
+			return string(b)
+		}
+	}
+	return "NONE"
+}
+
+func (o Option[T]) MarshalJSON() ([]byte, error) {
+	if o.state != optionSome {
+		return []byte("null"), nil
+	}
+	return json.MarshalNoEscape(o.value)
+}
+
+// UnmarshalJSON is only called for fields that are there; null is NULL.
+func (o *Option[T]) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*o = Null[T]()
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*o = Some(v)
+	return nil
+}
+
+func (o Option[T]) MarshalSurreal() ([]byte, error) {
+	switch o.state {
+	case optionNone:
+		return []byte("NONE"), nil
+	case optionNull:
+		return []byte("NULL"), nil
+	}
+	if m, ok := any(o.value).(SurrealMarshalable); ok {
+		return m.MarshalSurreal()
+	}
+	return json.MarshalNoEscape(o.value)
+}
+
+// SurrealParam implements SurrealParam: NONE is NONE, NULL is NULL, and a
+// value is bound like it would be on its own, see BindParam.
+func (o Option[T]) SurrealParam() (string, any) {
+	switch o.state {
+	case optionNone:
+		return None.SurrealParam()
+	case optionNull:
+		return "$", nil
+	}
+	return BindParam(o.value)
+}
+
+// Scan implements sql.Scanner; nil is NULL. JSON ([]byte) is decoded into T
+// if T can not take it as is.
+func (o *Option[T]) Scan(src any) error {
+	if src == nil {
+		*o = Null[T]()
+		return nil
+	}
+	var v T
+	if scanner, ok := any(&v).(sql.Scanner); ok {
+		if err := scanner.Scan(src); err != nil {
+			return err
+		}
+		*o = Some(v)
+		return nil
+	}
+	var n sql.Null[T]
+	if err := n.Scan(src); err != nil {
+		b, ok := src.([]byte)
+		if !ok {
+			return err
+		}
+		if err := json.Unmarshal(b, &v); err != nil {
+			return err
+		}
+		*o = Some(v)
+		return nil
+	}
+	*o = Some(n.V)
+	return nil
+}
+
+// Value implements driver.Valuer; NONE and NULL are both nil. Passed to a
+// query directly, NONE is kept, see SurrealParam.
+func (o Option[T]) Value() (driver.Value, error) {
+	if o.state != optionSome {
+		return nil, nil
+	}
+	if valuer, ok := any(o.value).(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return driver.DefaultParameterConverter.ConvertValue(o.value)
+}
+
+// GormDataType names the field's type for GORM's migrator, i.e.
+// option<string>.
+func (o Option[T]) GormDataType() string {
+	return "option<" + SurrealKind(reflect.TypeOf((*T)(nil)).Elem()) + ">"
+}
+
+// SurrealKind is the SurrealQL type of a Go type, as far as it is obvious,
+// i.e. decimal for a Decimal, record<users> for an ID[Users] and
+// option<string> for an Option[string]. Pointers are the type they point
+// to; types without an obvious one are any.
+func SurrealKind(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(time.Time{}), reflect.TypeOf(DateTime{}):
+		return "datetime"
+	case reflect.TypeOf(NullDateTime{}):
+		return "datetime | null"
+	case reflect.TypeOf(time.Duration(0)), reflect.TypeOf(Duration{}):
+		return "duration"
+	case reflect.TypeOf(Decimal{}):
+		return "decimal"
+	}
+	switch v := reflect.Zero(t).Interface().(type) {
+	case interface{ GormDataType() string }:
+		// Option[T]
+		return v.GormDataType()
+	case interface {
+		Table() string
+		Record() SurrealDBRecordID
+	}:
+		// ID[T]
+		return "record<" + formatTable(v.Table()) + ">"
+	}
+	if t.Implements(reflect.TypeOf((*SurrealDBRecordID)(nil)).Elem()) {
+		return "record"
+	}
+	if t.Implements(reflect.TypeOf((*GeometryValue)(nil)).Elem()) {
+		if t.Kind() == reflect.Interface {
+			return "geometry"
+		}
+		return geometryKind(reflect.Zero(t).Interface().(GeometryValue))
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes"
+		}
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return "any"
+}
@@ -5,13 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 
 	"github.com/goccy/go-json"
 	"github.com/tidwall/gjson"
 )
 
-var recordTKemba = localKemba.Extend("Record[T]")
-
 type Record[T any] struct {
 	inner   T
 	id      SurrealDBRecordID
@@ -60,9 +59,13 @@ func (r *Record[T]) GetID() (SurrealDBRecordID, bool) {
 }
 
 func (r *Record[T]) UnmarshalJSON(b []byte) error {
-	k := recordTKemba.Extend("UnmarshalJSON")
 	data := gjson.ParseBytes(b)
-	k.Printf("in: %s", data.Raw)
+
+	// NULL; NONE never gets here, the field is just not there.
+	if data.Type == gjson.Null {
+		*r = Record[T]{}
+		return nil
+	}
 
 	if r.innerIsSlice() {
 		return errors.New("surrealtypes/record: T is a slice, expected a single type (ment st.Records[T]?)")
@@ -72,13 +75,11 @@ func (r *Record[T]) UnmarshalJSON(b []byte) error {
 
 	// A string is an ID
 	if data.Type == gjson.String {
-		k.Log("Only a string was given; treating it as an ID") // TODO: "VerifyID(str)"?
+		// TODO: "VerifyID(str)"?
 		id, err := ParseID(data.String())
 		if err != nil {
-			k.Printf("Saw error: %s", err.Error())
 			return err
 		}
-		k.Log(id, err)
 		r.id = id
 		r.hasData = false
 		r.hasId = true
@@ -86,9 +87,7 @@ func (r *Record[T]) UnmarshalJSON(b []byte) error {
 	}
 
 	// This is mainly for safety: A record should be an object.
-	k.Log("It's a normal object, deserialize it")
 	id, err := ParseID(data.Get("id").String())
-	k.Log("done id", id.SurrealString(), err)
 	if err != nil {
 		return err
 	}
@@ -96,39 +95,35 @@ func (r *Record[T]) UnmarshalJSON(b []byte) error {
 	r.hasData = true
 	r.hasId = true
 	err = json.Unmarshal(b, &r.inner)
-	k.Log("done obj", r.inner, err)
 	return err
 }
 
 func (r *Record[T]) MarshalJSON() ([]byte, error) {
-	k := recordTKemba.Extend("MarshalJSON")
 	switch {
 	case r.hasData && r.hasId:
-		k.Log("hasData && hasId", r.inner)
 		return json.MarshalNoEscape(r.inner)
 	case !r.hasData && r.hasId:
-		k.Log("!hasData && hasId")
 		return json.MarshalNoEscape(r.id.SurrealString())
 	case !r.hasData && !r.hasId:
-		k.Log("!hasData && !hasId")
 		return []byte("null"), nil
 	}
 	panic("unreachable Record[T].MarshalJSON(...)")
 }
 
 func (r *Record[T]) Scan(src any) error {
-	k := recordTKemba.Extend("Scan")
 	switch data := src.(type) {
+	case nil:
+		*r = Record[T]{}
+		return nil
 	case []byte:
-		k.Log("Decoding bytes")
 		return r.UnmarshalJSON(data)
+	case string:
+		return r.UnmarshalJSON([]byte(strconv.Quote(data)))
 	default:
-		k.Printf("Got: %T", src)
 		return fmt.Errorf("input must be []byte, found %T", src)
 	}
 }
 
 func (r *Record[T]) Value() (driver.Value, error) {
-	recordTKemba.Extend("Value").Log("-> MarshalJSON")
 	return r.MarshalJSON()
 }
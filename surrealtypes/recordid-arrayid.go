@@ -0,0 +1,78 @@
+package surrealtypes
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ArrayID is a record ID with an array key, i.e.
+// temperature:['london', d'2024-01-01T00:00:00Z']. Such keys are ordered
+// element by element, which makes them the usual key of time series; see
+// PrefixRange for scanning them.
+//
+// Elements are nil (NULL), None, Unbounded, bool, int64, float64, Decimal,
+// Duration, string, time.Time, uuid.UUID, record IDs, []any and
+// map[string]any, as ParseID returns them; other integers and slices are
+// accepted too.
+type ArrayID struct {
+	Table string
+	Thing []any
+}
+
+var _ (SurrealDBRecordID) = (*ArrayID)(nil)
+var _ (SurrealParam) = (*ArrayID)(nil)
+
+// NewArrayID is the ID of table with the array of elems as key.
+func NewArrayID(table string, elems ...any) ArrayID {
+	return ArrayID{Table: table, Thing: elems}
+}
+
+// SurrealString implements SurrealDBRecordID.
+func (id ArrayID) SurrealString() string {
+	out := strings.Builder{}
+	out.WriteString(formatTable(id.Table))
+	out.WriteByte(':')
+	formatValue(&out, id.Thing)
+	return out.String()
+}
+
+func (id *ArrayID) UnmarshalJSON(b []byte) error {
+	genId, err := ParseID(string(b))
+	if err != nil {
+		return err
+	}
+	newId, ok := genId.(ArrayID)
+	if !ok {
+		return fmt.Errorf("record ID %s is a %T, not a ArrayID", genId.SurrealString(), genId)
+	}
+	*id = newId
+	return nil
+}
+func (id *ArrayID) MarshalJSON() ([]byte, error) {
+	s := strconv.QuoteToGraphic(id.SurrealString())
+	return []byte(s), nil
+}
+func (id *ArrayID) Scan(src any) error {
+	switch data := src.(type) {
+	case []byte:
+		return id.UnmarshalJSON(data)
+	case string:
+		return id.UnmarshalJSON([]byte(data))
+	default:
+		return fmt.Errorf("input must be []byte or string, found %T", src)
+	}
+}
+func (id *ArrayID) Value() (driver.Value, error) {
+	return id.MarshalJSON()
+}
+
+// SurrealParam implements SurrealParam. Elements JSON has no type for, i.e.
+// datetimes and record links, are cast one by one; as strings, they would
+// make another key.
+func (id ArrayID) SurrealParam() (string, any) {
+	vars := map[string]any{"tb": id.Table}
+	key := bindValue(id.Thing, vars, "key")
+	return "type::thing($.tb, " + key + ")", vars
+}
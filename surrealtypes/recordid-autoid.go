@@ -22,10 +22,11 @@ type AutoID struct {
 }
 
 var _ (SurrealDBRecordID) = (*AutoID)(nil)
+var _ (SurrealParam) = (*AutoID)(nil)
 
 func (id AutoID) SurrealString() string {
 	out := strings.Builder{}
-	out.WriteString(id.Table)
+	out.WriteString(formatTable(id.Table))
 	out.WriteByte(':')
 	out.WriteString(string(id.Thing))
 	return out.String()
@@ -36,7 +37,10 @@ func (id *AutoID) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	newId := genId.(AutoID)
+	newId, ok := genId.(AutoID)
+	if !ok {
+		return fmt.Errorf("record ID %s is a %T, not a AutoID", genId.SurrealString(), genId)
+	}
 	*id = newId
 	return nil
 }
@@ -57,3 +61,14 @@ func (id *AutoID) Scan(src any) error {
 func (id *AutoID) Value() (driver.Value, error) {
 	return id.MarshalJSON()
 }
+
+// SurrealParam implements SurrealParam, binding the ID as a record link.
+func (id AutoID) SurrealParam() (string, any) {
+	switch id.Thing {
+	case AutoIDUUID:
+		return "type::thing($, rand::uuid())", id.Table
+	case AutoIDULID:
+		return "type::thing($, rand::ulid())", id.Table
+	}
+	return "type::table($)", id.Table
+}
@@ -13,13 +13,18 @@ type FloatID struct {
 }
 
 var _ (SurrealDBRecordID) = (*FloatID)(nil)
+var _ (SurrealParam) = (*FloatID)(nil)
 
 func (id FloatID) SurrealString() string {
 	out := strings.Builder{}
-	out.WriteString(id.Table)
+	out.WriteString(formatTable(id.Table))
 	out.WriteByte(':')
-	// TODO: Information loss...?
-	out.WriteString(strconv.FormatFloat(id.Thing, 'f', -1, 64))
+	f := strconv.FormatFloat(id.Thing, 'f', -1, 64)
+	if !strings.Contains(f, ".") {
+		// Or it would read back as an IntID.
+		f += ".0"
+	}
+	out.WriteString(f)
 	return out.String()
 }
 
@@ -28,7 +33,10 @@ func (id *FloatID) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	newId := genId.(FloatID)
+	newId, ok := genId.(FloatID)
+	if !ok {
+		return fmt.Errorf("record ID %s is a %T, not a FloatID", genId.SurrealString(), genId)
+	}
 	*id = newId
 	return nil
 }
@@ -49,3 +57,8 @@ func (id *FloatID) Scan(src any) error {
 func (id *FloatID) Value() (driver.Value, error) {
 	return id.MarshalJSON()
 }
+
+// SurrealParam implements SurrealParam, binding the ID as a record link.
+func (id FloatID) SurrealParam() (string, any) {
+	return recordParam(id.Table, id.Thing)
+}
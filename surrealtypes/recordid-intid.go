@@ -13,13 +13,13 @@ type IntID struct {
 }
 
 var _ (SurrealDBRecordID) = (*IntID)(nil)
+var _ (SurrealParam) = (*IntID)(nil)
 
 func (id IntID) SurrealString() string {
 	out := strings.Builder{}
-	out.WriteString(id.Table)
+	out.WriteString(formatTable(id.Table))
 	out.WriteByte(':')
-	// TODO: Information loss...?
-	out.WriteString(strconv.Itoa(int(id.Thing)))
+	out.WriteString(strconv.FormatInt(id.Thing, 10))
 	return out.String()
 }
 
@@ -28,7 +28,10 @@ func (id *IntID) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	newId := genId.(IntID)
+	newId, ok := genId.(IntID)
+	if !ok {
+		return fmt.Errorf("record ID %s is a %T, not a IntID", genId.SurrealString(), genId)
+	}
 	*id = newId
 	return nil
 }
@@ -49,3 +52,8 @@ func (id *IntID) Scan(src any) error {
 func (id *IntID) Value() (driver.Value, error) {
 	return id.MarshalJSON()
 }
+
+// SurrealParam implements SurrealParam, binding the ID as a record link.
+func (id IntID) SurrealParam() (string, any) {
+	return recordParam(id.Table, id.Thing)
+}
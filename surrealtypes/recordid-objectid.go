@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/goccy/go-json"
 	"github.com/tidwall/gjson"
 )
 
@@ -15,11 +16,12 @@ type ObjectID struct {
 }
 
 var _ (SurrealDBRecordID) = (*ObjectID)(nil)
+var _ (SurrealParam) = (*ObjectID)(nil)
 
 // SurrealString implements SurrealDBRecordID.
 func (id ObjectID) SurrealString() string {
 	out := strings.Builder{}
-	out.WriteString(id.Table)
+	out.WriteString(formatTable(id.Table))
 	out.WriteByte(':')
 	out.WriteString(id.Thing.Raw)
 	return out.String()
@@ -30,7 +32,10 @@ func (id *ObjectID) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	newId := genId.(ObjectID)
+	newId, ok := genId.(ObjectID)
+	if !ok {
+		return fmt.Errorf("record ID %s is a %T, not a ObjectID", genId.SurrealString(), genId)
+	}
 	*id = newId
 	return nil
 }
@@ -51,3 +56,8 @@ func (id *ObjectID) Scan(src any) error {
 func (id *ObjectID) Value() (driver.Value, error) {
 	return id.MarshalJSON()
 }
+
+// SurrealParam implements SurrealParam, binding the ID as a record link.
+func (id ObjectID) SurrealParam() (string, any) {
+	return recordParam(id.Table, json.RawMessage(id.Thing.Raw))
+}
@@ -0,0 +1,173 @@
+package surrealtypes
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RangeID is a range of record IDs of one table, i.e. person:1..=10 or
+// temperature:['london', NONE]..=['london', ..]. Either end may be left out.
+//
+// Selecting from a range reads only the records in it, rather than the whole
+// table:
+//
+//	db.Query("SELECT * FROM $_1", st.PrefixRange("temperature", "london"))
+type RangeID struct {
+	Table string
+	Begin *RangeBound // nil for no lower bound
+	End   *RangeBound // nil for no upper bound
+}
+
+// RangeBound is one end of a RangeID. Its key is of the kinds the key of
+// ID[T] is, see NewID; ParseID gives []any for arrays, as for ArrayID.
+type RangeBound struct {
+	Key       any
+	Inclusive bool
+}
+
+var _ (SurrealDBRecordID) = (*RangeID)(nil)
+var _ (SurrealParam) = (*RangeID)(nil)
+
+// Inclusive is a bound including key.
+func Inclusive(key any) *RangeBound {
+	return &RangeBound{Key: key, Inclusive: true}
+}
+
+// Exclusive is a bound excluding key.
+func Exclusive(key any) *RangeBound {
+	return &RangeBound{Key: key}
+}
+
+// PrefixRange is the range of all array keys starting with prefix, i.e.
+// temperature:['london', NONE]..=['london', ..] for
+// PrefixRange("temperature", "london").
+func PrefixRange(table string, prefix ...any) RangeID {
+	return RangeID{
+		Table: table,
+		Begin: Inclusive(withLast(prefix, None)),
+		End:   Inclusive(withLast(prefix, Unbounded)),
+	}
+}
+
+// PrefixBetween is the range of array keys starting with prefix and going
+// on with from up to, but not including, to. For a time series:
+//
+//	st.PrefixBetween("temperature", []any{"london"}, yesterday, today)
+//
+// is temperature:['london', d'…']..['london', d'…'].
+func PrefixBetween(table string, prefix []any, from, to any) RangeID {
+	return RangeID{
+		Table: table,
+		Begin: Inclusive(withLast(prefix, from)),
+		End:   Exclusive(withLast(prefix, to)),
+	}
+}
+
+func withLast(prefix []any, last any) []any {
+	return append(append(make([]any, 0, len(prefix)+1), prefix...), last)
+}
+
+// SurrealString implements SurrealDBRecordID. The start is inclusive unless
+// marked with '>', the end exclusive unless marked with '='.
+func (id RangeID) SurrealString() string {
+	out := strings.Builder{}
+	out.WriteString(formatTable(id.Table))
+	out.WriteByte(':')
+	if id.Begin != nil {
+		out.WriteString(id.boundText(id.Begin))
+		if !id.Begin.Inclusive {
+			out.WriteByte('>')
+		}
+	}
+	out.WriteString("..")
+	if id.End != nil {
+		if id.End.Inclusive {
+			out.WriteByte('=')
+		}
+		out.WriteString(id.boundText(id.End))
+	}
+	return out.String()
+}
+
+func (id RangeID) boundText(b *RangeBound) string {
+	key, err := makeID(id.Table, b.Key)
+	if err != nil {
+		out := strings.Builder{}
+		formatValue(&out, b.Key)
+		return out.String()
+	}
+	return keyText(key)
+}
+
+func (id *RangeID) UnmarshalJSON(b []byte) error {
+	genId, err := ParseID(string(b))
+	if err != nil {
+		return err
+	}
+	newId, ok := genId.(RangeID)
+	if !ok {
+		return fmt.Errorf("record ID %s is a %T, not a RangeID", genId.SurrealString(), genId)
+	}
+	*id = newId
+	return nil
+}
+func (id *RangeID) MarshalJSON() ([]byte, error) {
+	s := strconv.QuoteToGraphic(id.SurrealString())
+	return []byte(s), nil
+}
+func (id *RangeID) Scan(src any) error {
+	switch data := src.(type) {
+	case []byte:
+		return id.UnmarshalJSON(data)
+	case string:
+		return id.UnmarshalJSON([]byte(data))
+	default:
+		return fmt.Errorf("input must be []byte or string, found %T", src)
+	}
+}
+func (id *RangeID) Value() (driver.Value, error) {
+	return id.MarshalJSON()
+}
+
+// SurrealParam implements SurrealParam. A range has no JSON form, and
+// SurrealQL takes no variable as a whole bound: the range is written into
+// the query with the elements of array and object bounds as variables.
+// Other keys are written as in SurrealString, strings escaped.
+func (id RangeID) SurrealParam() (string, any) {
+	vars := map[string]any{}
+	out := strings.Builder{}
+	out.WriteString(formatTable(id.Table))
+	out.WriteByte(':')
+	if id.Begin != nil {
+		out.WriteString(id.boundParam(id.Begin, vars, "begin"))
+		if !id.Begin.Inclusive {
+			out.WriteByte('>')
+		}
+	}
+	out.WriteString("..")
+	if id.End != nil {
+		if id.End.Inclusive {
+			out.WriteByte('=')
+		}
+		out.WriteString(id.boundParam(id.End, vars, "end"))
+	}
+	return out.String(), vars
+}
+
+func (id RangeID) boundParam(b *RangeBound, vars map[string]any, name string) string {
+	switch key, _ := makeID(id.Table, b.Key); key := key.(type) {
+	case ArrayID:
+		return bindElems(key.Thing, vars, name)
+	case ObjectID:
+		if entries, ok := key.Thing.Value().(map[string]any); ok {
+			return bindEntries(entries, vars, name)
+		}
+		return bindElems(key.Thing.Value().([]any), vars, name)
+	case nil:
+		return bindValue(b.Key, vars, name)
+	default:
+		return keyText(key)
+	}
+}
@@ -13,17 +13,14 @@ type RawID struct {
 }
 
 var _ (SurrealDBRecordID) = (*RawID)(nil)
+var _ (SurrealParam) = (*RawID)(nil)
 
 // SurrealString implements SurrealDBRecordID.
 func (id RawID) SurrealString() string {
 	out := strings.Builder{}
-	out.WriteString(id.Table)
+	out.WriteString(formatTable(id.Table))
 	out.WriteByte(':')
-	out.WriteRune(SRIDOpen)
-	for _, r := range id.Thing {
-		out.WriteRune(r)
-	}
-	out.WriteRune(SRIDClose)
+	out.WriteString(escapeKey(string(id.Thing)))
 	return out.String()
 }
 
@@ -32,8 +29,15 @@ func (id *RawID) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	newId := genId.(RawID)
-	*id = newId
+	switch newId := genId.(type) {
+	case RawID:
+		*id = newId
+	case StringID:
+		// Escaped keys are read as StringID.
+		*id = RawID{Table: newId.Table, Thing: []rune(newId.Thing)}
+	default:
+		return fmt.Errorf("record ID %s is a %T, not a RawID", genId.SurrealString(), genId)
+	}
 	return nil
 }
 func (id *RawID) MarshalJSON() ([]byte, error) {
@@ -53,3 +57,13 @@ func (id *RawID) Scan(src any) error {
 func (id *RawID) Value() (driver.Value, error) {
 	return id.MarshalJSON()
 }
+
+// SurrealParam implements SurrealParam, binding the ID as a record link.
+func (id RawID) SurrealParam() (string, any) {
+	thing := string(id.Thing)
+	if len(id.Thing) >= 2 && (id.Thing[0] == SRIDOpen && id.Thing[len(id.Thing)-1] == SRIDClose ||
+		id.Thing[0] == '`' && id.Thing[len(id.Thing)-1] == '`') {
+		thing = string(id.Thing[1 : len(id.Thing)-1])
+	}
+	return recordParam(id.Table, thing)
+}
@@ -13,13 +13,14 @@ type StringID struct {
 }
 
 var _ (SurrealDBRecordID) = (*StringID)(nil)
+var _ (SurrealParam) = (*StringID)(nil)
 
 // SurrealString implements SurrealDBRecordID.
 func (id StringID) SurrealString() string {
 	out := strings.Builder{}
-	out.WriteString(id.Table)
+	out.WriteString(formatTable(id.Table))
 	out.WriteByte(':')
-	out.WriteString(id.Thing)
+	out.WriteString(formatString(id.Table, id.Thing))
 	return out.String()
 }
 
@@ -28,7 +29,10 @@ func (id *StringID) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	newId := genId.(StringID)
+	newId, ok := genId.(StringID)
+	if !ok {
+		return fmt.Errorf("record ID %s is a %T, not a StringID", genId.SurrealString(), genId)
+	}
 	*id = newId
 	return nil
 }
@@ -49,3 +53,8 @@ func (id *StringID) Scan(src any) error {
 func (id *StringID) Value() (driver.Value, error) {
 	return id.MarshalJSON()
 }
+
+// SurrealParam implements SurrealParam, binding the ID as a record link.
+func (id StringID) SurrealParam() (string, any) {
+	return recordParam(id.Table, id.Thing)
+}
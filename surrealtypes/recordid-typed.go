@@ -0,0 +1,231 @@
+package surrealtypes
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+
+	"github.com/goccy/go-json"
+	"github.com/gofrs/uuid/v5"
+	"github.com/oklog/ulid/v2"
+	"github.com/tidwall/gjson"
+)
+
+// TableNamer names the table of an ID. It is called on the zero value, so
+// an empty struct type does, and so does a model with a TableName method:
+//
+//	type Users struct{}
+//
+//	func (Users) TableName() string { return "users" }
+type TableNamer interface {
+	TableName() string
+}
+
+// ID is a record ID of the table T names, with a key of any kind:
+//
+//	type Post struct {
+//		Author st.ID[Users] `json:"author"`
+//	}
+//
+// Decoding an ID of another table fails, and an ID[Users] can not be
+// assigned to an ID[Posts]. The zero value is no ID; it marshals as null.
+type ID[T TableNamer] struct {
+	id SurrealDBRecordID
+}
+
+var _ SurrealDBRecordID = ID[TableNamer]{}
+var _ SurrealParam = ID[TableNamer]{}
+var _ json.Marshaler = (*ID[TableNamer])(nil)
+var _ json.Unmarshaler = (*ID[TableNamer])(nil)
+var _ driver.Valuer = (*ID[TableNamer])(nil)
+var _ sql.Scanner = (*ID[TableNamer])(nil)
+
+// NewID makes an ID of T's table. The key may be an integer, float64,
+// string, uuid.UUID, ulid.ULID, AutoIDFunc, a slice or array, which becomes
+// an ArrayID, or a map, struct or gjson.Result holding an object, which
+// becomes an ObjectID.
+func NewID[T TableNamer](key any) (ID[T], error) {
+	id, err := makeID(tableOf[T](), key)
+	if err != nil {
+		return ID[T]{}, err
+	}
+	return ID[T]{id: id}, nil
+}
+
+// The ID of table with key, see NewID.
+func makeID(table string, key any) (SurrealDBRecordID, error) {
+	switch k := key.(type) {
+	case int64:
+		return IntID{Table: table, Thing: k}, nil
+	case float64:
+		return FloatID{Table: table, Thing: k}, nil
+	case float32:
+		return FloatID{Table: table, Thing: float64(k)}, nil
+	case string:
+		return StringID{Table: table, Thing: k}, nil
+	case uuid.UUID:
+		return UUIDID{Table: table, Thing: k}, nil
+	case ulid.ULID:
+		return ULIDID{Table: table, Thing: k}, nil
+	case AutoIDFunc:
+		return AutoID{Table: table, Thing: k}, nil
+	case []any:
+		return ArrayID{Table: table, Thing: k}, nil
+	case gjson.Result:
+		if k.IsArray() || k.IsObject() {
+			return ObjectID{Table: table, Thing: k}, nil
+		}
+		return nil, fmt.Errorf("%s can not be the key of a record ID", k.Raw)
+	}
+	if i, ok := anyInt(key); ok {
+		return IntID{Table: table, Thing: i}, nil
+	}
+	if elems, ok := anySlice(key); ok {
+		return ArrayID{Table: table, Thing: elems}, nil
+	}
+	b, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+	if thing := gjson.ParseBytes(b); thing.IsObject() {
+		return ObjectID{Table: table, Thing: thing}, nil
+	}
+	return nil, fmt.Errorf("%T can not be the key of a record ID", key)
+}
+
+// MustID is NewID, panicking on error.
+func MustID[T TableNamer](key any) ID[T] {
+	id, err := NewID[T](key)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// IDFrom converts an ID of any type, failing if it is of another table.
+func IDFrom[T TableNamer](id SurrealDBRecordID) (ID[T], error) {
+	if typed, ok := id.(interface{ Record() SurrealDBRecordID }); ok {
+		id = typed.Record()
+	}
+	if id == nil {
+		return ID[T]{}, nil
+	}
+	table, _, ok := splitID(id.SurrealString())
+	if !ok {
+		return ID[T]{}, fmt.Errorf("record ID %s has no table", id.SurrealString())
+	}
+	if want := formatTable(tableOf[T]()); table != want {
+		return ID[T]{}, fmt.Errorf("record ID %s is not of table %s", id.SurrealString(), want)
+	}
+	return ID[T]{id: id}, nil
+}
+
+func tableOf[T TableNamer]() string {
+	var t T
+	return t.TableName()
+}
+
+// Table is the name of T's table.
+func (id ID[T]) Table() string {
+	return tableOf[T]()
+}
+
+// Record is the ID as one of the untyped ID types, i.e. IntID; nil if
+// there is none.
+func (id ID[T]) Record() SurrealDBRecordID {
+	return id.id
+}
+
+// IsZero tells whether there is no ID.
+func (id ID[T]) IsZero() bool {
+	return id.id == nil
+}
+
+// Key is the key without the table: int64, float64, string, uuid.UUID,
+// ulid.ULID, AutoIDFunc, []any for arrays, a gjson.Result for objects, and
+// the untyped ID itself for ranges.
+func (id ID[T]) Key() any {
+	return idKey(id.id)
+}
+
+// The key of id, see ID.Key; the inverse of makeID.
+func idKey(id SurrealDBRecordID) any {
+	switch k := id.(type) {
+	case IntID:
+		return k.Thing
+	case FloatID:
+		return k.Thing
+	case StringID:
+		return k.Thing
+	case RawID:
+		return string(k.Thing)
+	case UUIDID:
+		return k.Thing
+	case ULIDID:
+		return k.Thing
+	case AutoID:
+		return k.Thing
+	case ArrayID:
+		return k.Thing
+	case ObjectID:
+		return k.Thing
+	}
+	return id
+}
+
+// SurrealString implements SurrealDBRecordID; "" if there is no ID.
+func (id ID[T]) SurrealString() string {
+	if id.id == nil {
+		return ""
+	}
+	return id.id.SurrealString()
+}
+
+// SurrealParam implements SurrealParam, binding the ID as a record link.
+func (id ID[T]) SurrealParam() (string, any) {
+	if id.id == nil {
+		return "$", nil
+	}
+	return BindParam(id.id)
+}
+
+func (id *ID[T]) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*id = ID[T]{}
+		return nil
+	}
+	genId, err := ParseID(string(b))
+	if err != nil {
+		return err
+	}
+	typed, err := IDFrom[T](genId)
+	if err != nil {
+		return err
+	}
+	*id = typed
+	return nil
+}
+func (id ID[T]) MarshalJSON() ([]byte, error) {
+	if id.id == nil {
+		return []byte("null"), nil
+	}
+	s := strconv.QuoteToGraphic(id.id.SurrealString())
+	return []byte(s), nil
+}
+func (id *ID[T]) Scan(src any) error {
+	switch data := src.(type) {
+	case nil:
+		*id = ID[T]{}
+		return nil
+	case []byte:
+		return id.UnmarshalJSON(data)
+	case string:
+		return id.UnmarshalJSON([]byte(strconv.Quote(data)))
+	default:
+		return fmt.Errorf("input must be []byte or string, found %T", src)
+	}
+}
+func (id ID[T]) Value() (driver.Value, error) {
+	return id.MarshalJSON()
+}
@@ -15,12 +15,13 @@ type ULIDID struct {
 }
 
 var _ (SurrealDBRecordID) = (*ULIDID)(nil)
+var _ (SurrealParam) = (*ULIDID)(nil)
 
 func (id ULIDID) SurrealString() string {
 	out := strings.Builder{}
-	out.WriteString(id.Table)
+	out.WriteString(formatTable(id.Table))
 	out.WriteByte(':')
-	out.Write(id.Thing.Bytes())
+	out.WriteString(id.Thing.String())
 	return out.String()
 }
 
@@ -29,7 +30,10 @@ func (id *ULIDID) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	newId := genId.(ULIDID)
+	newId, ok := genId.(ULIDID)
+	if !ok {
+		return fmt.Errorf("record ID %s is a %T, not a ULIDID", genId.SurrealString(), genId)
+	}
 	*id = newId
 	return nil
 }
@@ -50,3 +54,8 @@ func (id *ULIDID) Scan(src any) error {
 func (id *ULIDID) Value() (driver.Value, error) {
 	return id.MarshalJSON()
 }
+
+// SurrealParam implements SurrealParam, binding the ID as a record link.
+func (id ULIDID) SurrealParam() (string, any) {
+	return recordParam(id.Table, id.Thing.String())
+}
@@ -15,14 +15,13 @@ type UUIDID struct {
 }
 
 var _ (SurrealDBRecordID) = (*UUIDID)(nil)
+var _ (SurrealParam) = (*UUIDID)(nil)
 
 func (id UUIDID) SurrealString() string {
 	out := strings.Builder{}
-	out.WriteString(id.Table)
+	out.WriteString(formatTable(id.Table))
 	out.WriteByte(':')
-	out.WriteRune(SRIDOpen)
-	out.WriteString(id.Thing.String())
-	out.WriteRune(SRIDClose)
+	out.WriteString(escapeKey(id.Thing.String()))
 	return out.String()
 }
 
@@ -31,7 +30,10 @@ func (id *UUIDID) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	newId := genId.(UUIDID)
+	newId, ok := genId.(UUIDID)
+	if !ok {
+		return fmt.Errorf("record ID %s is a %T, not a UUIDID", genId.SurrealString(), genId)
+	}
 	*id = newId
 	return nil
 }
@@ -52,3 +54,8 @@ func (id *UUIDID) Scan(src any) error {
 func (id *UUIDID) Value() (driver.Value, error) {
 	return id.MarshalJSON()
 }
+
+// SurrealParam implements SurrealParam, binding the ID as a record link.
+func (id UUIDID) SurrealParam() (string, any) {
+	return recordParam(id.Table, id.Thing.String())
+}
@@ -1,113 +1,403 @@
-package surrealtypes
-
-import (
-	"fmt"
-	"strconv"
-	"time"
-
-	"github.com/gofrs/uuid/v5"
-	"github.com/oklog/ulid/v2"
-	"github.com/tidwall/gjson"
-)
-
-const (
-	// Footgun: Those aren't paranthesis, brackets, or anything alike.
-	// ... they're _unicode_. o.o
-	SRIDOpen  rune = '⟨'
-	SRIDClose rune = '⟩'
-)
-
-type SurrealDBRecordID interface {
-	SurrealString() string
-}
-
-type IDThings interface {
-	int64 | float64 | []rune | gjson.Result | ulid.ULID | uuid.UUID | time.Time | AutoIDFunc
-}
-
-func ParseID(in string) (SurrealDBRecordID, error) {
-	k := localKemba.Extend("NewRecord")
-
-	isBracket := func(b []rune) bool {
-		// can i avoid the copy?
-		return b[0] == SRIDOpen && b[len(b)-1] == SRIDClose
-	}
-	isTicks := func(b []rune) bool {
-		return b[0] == '`' && b[len(b)-1] == '`'
-	}
-	var left, right []rune
-	var hasId bool = false
-	var srid SurrealDBRecordID
-	for _, b := range in {
-		if b == ':' {
-			hasId = true
-			continue
-		}
-		if !hasId {
-			left = append(left, b)
-		} else {
-			right = append(right, b)
-		}
-	}
-	k.Printf("Scanned <%s> : <%s> (%s)", string(left), string(right), string(in))
-	if len(left) <= 0 || len(right) <= 0 {
-		return nil, fmt.Errorf("unaligned RecordID: %v, %v : %v", left, right, in)
-	}
-	if len(left) >= 2 && left[0] == '\\' && left[1] == '"' {
-		left = left[2:]
-	}
-	if len(right) >= 2 && right[len(right)-2] == '\\' && right[len(right)-1] == '"' {
-		right = right[:len(right)-2]
-	}
-	// TODO(IP): Sometimes there is JUST a single quote here.
-	// I should debug that, but I am a bit short on time. So, here is a classic monkey-donkey-patch.
-	if len(left) >= 1 && left[0] == '"' {
-		left = left[1:]
-	}
-	if len(right) >= 1 && right[len(right)-1] == '"' {
-		right = right[:len(right)-1]
-	}
-	if isBracket(right) || isTicks(right) {
-		// -> tablename:`abc-def-ghi`
-		// -> tablename:⟨abc-def-ghi⟩
-		// Assume a string value.
-		k.Log("Raw ID")
-		srid = RawID{Table: string(left), Thing: right}
-	} else if i, err := strconv.ParseInt(string(right), 10, 64); err == nil {
-		k.Log("Integer ID")
-		srid = IntID{
-			Table: string(left),
-			Thing: i,
-		}
-	} else if f, err := strconv.ParseFloat(string(right), 64); err == nil {
-		k.Log("Float ID")
-		srid = FloatID{
-			Table: string(left),
-			Thing: f,
-		}
-	} else if ulid_id, err := ulid.ParseStrict(string(right)); err == nil {
-		k.Log("ULID ID")
-		srid = ULIDID{
-			Table: string(left),
-			Thing: ulid_id,
-		}
-	} else if uuid_id, err := uuid.FromString(string(right)); err == nil {
-		k.Log("UUID ID")
-		srid = UUIDID{
-			Table: string(left),
-			Thing: uuid_id,
-		}
-	} else if gjson.Valid(string(right)) {
-		k.Log("Object ID")
-		srid = ObjectID{
-			Table: string(left),
-			Thing: gjson.Parse(string(right)),
-		}
-	} else {
-		k.Log("String ID (fallthrough)")
-		srid = StringID{Table: string(left), Thing: string(right)}
-	}
-	// TODO: Range
-	// Any other formatting is literally SurrealQL and I can not parse that.
-	return srid, nil
-}
+package surrealtypes
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/gofrs/uuid/v5"
+	"github.com/oklog/ulid/v2"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// Footgun: Those aren't paranthesis, brackets, or anything alike.
+	// ... they're _unicode_. o.o
+	SRIDOpen  rune = '⟨'
+	SRIDClose rune = '⟩'
+)
+
+type SurrealDBRecordID interface {
+	SurrealString() string
+}
+
+type IDThings interface {
+	int64 | float64 | []rune | gjson.Result | ulid.ULID | uuid.UUID | time.Time | AutoIDFunc
+}
+
+// ParseID parses a record ID as SurrealDB writes it, i.e. person:tobie,
+// person:⟨tobie⟩, person:`tobie`, person:123, person:u'…', person:[1, 2],
+// person:{a: 1}, person:1..=10 or ⟨my-table⟩:1. The ID may also be given as a
+// JSON string, or as an r'…' literal.
+//
+// The key decides the type: integers are IntID, decimals FloatID, UUIDs
+// UUIDID, ULIDs ULIDID, arrays ArrayID, objects ObjectID, rand(), uuid()
+// and ulid() AutoID, ranges RangeID and everything else StringID. Arrays
+// holding anything but literals, i.e. function calls, are ObjectID as well.
+// ParseID(id.SurrealString()) gives back an equal ID for every ID ParseID
+// returns.
+func ParseID(in string) (SurrealDBRecordID, error) {
+	in = strings.TrimSpace(in)
+	if strings.HasPrefix(in, `"`) {
+		// A JSON string, as handed to UnmarshalJSON.
+		var s string
+		if err := json.Unmarshal([]byte(in), &s); err != nil {
+			return nil, fmt.Errorf("record ID %s: %w", in, err)
+		}
+		in = strings.TrimSpace(s)
+	}
+	if len(in) >= 3 && in[0] == 'r' && (in[1] == '\'' || in[1] == '"') && in[len(in)-1] == in[1] {
+		in = in[2 : len(in)-1]
+	}
+
+	p := &idParser{in: in}
+	id, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("record ID %q: %w", in, err)
+	}
+	return id, nil
+}
+
+var errNoKey = errors.New("no key")
+
+type idParser struct {
+	in  string
+	pos int
+}
+
+func (p *idParser) rest() string {
+	return p.in[p.pos:]
+}
+
+func (p *idParser) parse() (SurrealDBRecordID, error) {
+	table, err := p.ident()
+	if err != nil {
+		return nil, fmt.Errorf("table: %w", err)
+	}
+	if !strings.HasPrefix(p.rest(), ":") {
+		return nil, errors.New("no ':' after the table")
+	}
+	p.pos++
+
+	if isRangeOp(p.rest()) {
+		return p.rangeID(table, nil)
+	}
+	id, err := p.key(table)
+	if err != nil {
+		return nil, err
+	}
+	if isRangeOp(p.rest()) {
+		return p.rangeID(table, id)
+	}
+	if p.pos != len(p.in) {
+		return nil, fmt.Errorf("unexpected %q after the key", p.rest())
+	}
+	return id, nil
+}
+
+// A table name: plain or escaped.
+func (p *idParser) ident() (string, error) {
+	if s, ok, err := p.escaped(); ok || err != nil {
+		return s, err
+	}
+	start := p.pos
+	for p.pos < len(p.in) && isIdentChar(p.in[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", errors.New("empty")
+	}
+	return p.in[start:p.pos], nil
+}
+
+// A string in ⟨⟩ or backticks, with \ escapes.
+func (p *idParser) escaped() (string, bool, error) {
+	var closing string
+	switch {
+	case strings.HasPrefix(p.rest(), string(SRIDOpen)):
+		closing = string(SRIDClose)
+		p.pos += len(string(SRIDOpen))
+	case strings.HasPrefix(p.rest(), "`"):
+		closing = "`"
+		p.pos++
+	default:
+		return "", false, nil
+	}
+	out := strings.Builder{}
+	for p.pos < len(p.in) {
+		switch {
+		case p.in[p.pos] == '\\' && p.pos+1 < len(p.in):
+			p.pos++
+			if strings.HasPrefix(p.rest(), closing) {
+				out.WriteString(closing)
+				p.pos += len(closing)
+			} else {
+				out.WriteByte(p.in[p.pos])
+				p.pos++
+			}
+		case strings.HasPrefix(p.rest(), closing):
+			p.pos += len(closing)
+			return out.String(), true, nil
+		default:
+			out.WriteByte(p.in[p.pos])
+			p.pos++
+		}
+	}
+	return "", true, errors.New("unterminated " + closing)
+}
+
+func (p *idParser) key(table string) (SurrealDBRecordID, error) {
+	rest := p.rest()
+	if rest == "" {
+		return nil, errNoKey
+	}
+
+	if s, ok, err := p.escaped(); err != nil {
+		return nil, err
+	} else if ok {
+		// SurrealDB 1.x writes UUID keys as escaped strings.
+		if u, err := uuid.FromString(s); err == nil && len(s) == 36 {
+			return UUIDID{Table: table, Thing: u}, nil
+		}
+		return StringID{Table: table, Thing: s}, nil
+	}
+
+	switch rest[0] {
+	case '[':
+		start := p.pos
+		elems, err := p.array()
+		if err == nil {
+			return ArrayID{Table: table, Thing: elems}, nil
+		} else if errors.Is(err, errNaN) {
+			return nil, err
+		}
+		p.pos = start
+		fallthrough
+	case '{':
+		raw, err := p.balanced()
+		if err != nil {
+			return nil, err
+		}
+		return ObjectID{Table: table, Thing: gjson.Parse(raw)}, nil
+	case 'u':
+		if len(rest) > 1 && (rest[1] == '\'' || rest[1] == '"') {
+			end := strings.IndexByte(rest[2:], rest[1])
+			if end < 0 {
+				return nil, errors.New("unterminated UUID")
+			}
+			u, err := uuid.FromString(rest[2 : 2+end])
+			if err != nil {
+				return nil, err
+			}
+			p.pos += end + 3
+			return UUIDID{Table: table, Thing: u}, nil
+		}
+	}
+
+	start := p.pos
+	for p.pos < len(p.in) {
+		c := p.in[p.pos]
+		if isIdentChar(c) || c == '-' || (c == '.' && !isRangeOp(p.rest())) {
+			p.pos++
+			continue
+		}
+		break
+	}
+	bare := p.in[start:p.pos]
+	if strings.HasPrefix(p.rest(), "()") {
+		switch fn := AutoIDFunc(bare + "()"); fn {
+		case AutoIDRand, AutoIDUUID, AutoIDULID:
+			p.pos += 2
+			return AutoID{Table: table, Thing: fn}, nil
+		}
+	}
+	if bare == "" {
+		return nil, fmt.Errorf("unexpected %q", rest)
+	}
+	return bareKey(table, bare), nil
+}
+
+// Classifies a key that is not escaped.
+func bareKey(table, key string) SurrealDBRecordID {
+	if isInteger(key) {
+		if i, err := strconv.ParseInt(key, 10, 64); err == nil {
+			return IntID{Table: table, Thing: i}
+		}
+		// Too large for an integer; SurrealDB keeps it as a string.
+		return StringID{Table: table, Thing: key}
+	}
+	if whole, frac, ok := strings.Cut(key, "."); ok && isInteger(whole) && isDigits(frac) {
+		if f, err := strconv.ParseFloat(key, 64); err == nil {
+			return FloatID{Table: table, Thing: f}
+		}
+	}
+	if u, err := uuid.FromString(key); err == nil && len(key) == 36 {
+		return UUIDID{Table: table, Thing: u}
+	}
+	if u, err := ulid.ParseStrict(key); err == nil {
+		return ULIDID{Table: table, Thing: u}
+	}
+	return StringID{Table: table, Thing: key}
+}
+
+// An array or object, taken as is up to the matching bracket.
+func (p *idParser) balanced() (string, error) {
+	start := p.pos
+	depth := 0
+	for p.pos < len(p.in) {
+		c := p.in[p.pos]
+		switch {
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == '\'' || c == '"':
+			end := p.pos + 1
+			for end < len(p.in) && p.in[end] != c {
+				if p.in[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(p.in) {
+				return "", errors.New("unterminated string")
+			}
+			p.pos = end
+		case strings.HasPrefix(p.rest(), string(SRIDOpen)) || c == '`':
+			if _, _, err := p.escaped(); err != nil {
+				return "", err
+			}
+			continue
+		}
+		p.pos++
+		if depth == 0 {
+			return p.in[start:p.pos], nil
+		}
+	}
+	return "", errors.New("unterminated " + p.in[start:start+1])
+}
+
+func (p *idParser) rangeID(table string, begin SurrealDBRecordID) (SurrealDBRecordID, error) {
+	r := RangeID{Table: table}
+	if begin != nil {
+		r.Begin = Inclusive(idKey(begin))
+	}
+	if strings.HasPrefix(p.rest(), ">") {
+		if begin == nil {
+			return nil, errors.New("'>' without a start")
+		}
+		r.Begin.Inclusive = false
+		p.pos++
+	}
+	p.pos += 2
+	inclusive := false
+	if strings.HasPrefix(p.rest(), "=") {
+		inclusive = true
+		p.pos++
+	}
+	if p.pos == len(p.in) {
+		if inclusive {
+			return nil, errors.New("'..=' without an end")
+		}
+		return r, nil
+	}
+	end, err := p.key(table)
+	if err != nil {
+		return nil, fmt.Errorf("range end: %w", err)
+	}
+	if p.pos != len(p.in) {
+		return nil, fmt.Errorf("unexpected %q after the range", p.rest())
+	}
+	r.End = &RangeBound{Key: idKey(end), Inclusive: inclusive}
+	return r, nil
+}
+
+// The key of an ID as written in SurrealQL, without the table.
+func keyText(id SurrealDBRecordID) string {
+	_, key, _ := splitID(id.SurrealString())
+	return key
+}
+
+// TableOf is the table of a record ID as ParseID reads it, i.e. person for
+// person:tobie and my-table for ⟨my-table⟩:1; ok is false if there is none.
+// Only the table is read, not the key.
+func TableOf(id string) (table string, ok bool) {
+	p := &idParser{in: id}
+	table, err := p.ident()
+	if err != nil || !strings.HasPrefix(p.rest(), ":") {
+		return "", false
+	}
+	return table, true
+}
+
+// QuoteTable writes a table name the way record IDs do: as is if it is an
+// identifier, in ⟨⟩ with escapes otherwise.
+func QuoteTable(table string) string {
+	return formatTable(table)
+}
+
+// Splits table and key at the first ':' outside of an escaped table name.
+func splitID(s string) (string, string, bool) {
+	p := &idParser{in: s}
+	if _, err := p.ident(); err != nil || !strings.HasPrefix(p.rest(), ":") {
+		return "", "", false
+	}
+	return s[:p.pos], s[p.pos+1:], true
+}
+
+func isRangeOp(s string) bool {
+	return strings.HasPrefix(s, "..") || strings.HasPrefix(s, ">..")
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isInteger(s string) bool {
+	return isDigits(strings.TrimPrefix(s, "-"))
+}
+
+// A table name, escaped unless it is a plain identifier.
+func formatTable(table string) string {
+	for i := 0; i < len(table); i++ {
+		if !isIdentChar(table[i]) {
+			return escapeKey(table)
+		}
+	}
+	if table == "" {
+		return escapeKey(table)
+	}
+	return table
+}
+
+// A string key, escaped unless it would read back as the same string.
+func formatString(table, key string) string {
+	for i := 0; i < len(key); i++ {
+		if !isIdentChar(key[i]) {
+			return escapeKey(key)
+		}
+	}
+	if id, ok := bareKey(table, key).(StringID); !ok || id.Thing != key || key == "" {
+		return escapeKey(key)
+	}
+	return key
+}
+
+// Wraps s in ⟨⟩.
+func escapeKey(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, string(SRIDClose), `\`+string(SRIDClose))
+	return string(SRIDOpen) + s + string(SRIDClose)
+}
@@ -0,0 +1,245 @@
+package surrealtypes_test
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
+	"github.com/gofrs/uuid/v5"
+	"github.com/oklog/ulid/v2"
+	"github.com/tidwall/gjson"
+)
+
+var idSeeds = []string{
+	"person:tobie",
+	"person:⟨tobie⟩",
+	"person:`tobie`",
+	"person:⟨tobie-the-great⟩",
+	`person:⟨esc\⟩aped⟩`,
+	"person:123",
+	"person:-5",
+	"person:⟨123⟩",
+	"person:99999999999999999999",
+	"person:1.5",
+	"person:u'0190d7fc-ca2d-7c87-9a1c-5a6e6bdcd1ad'",
+	"person:⟨0190d7fc-ca2d-7c87-9a1c-5a6e6bdcd1ad⟩",
+	"person:01ARZ3NDEKTSV4RRFFQ69G5FAV",
+	"temperature:['london', d'2024-01-01T00:00:00Z']",
+	"person:{name: 'Tobie', 'a]b': 1}",
+	"person:rand()",
+	"person:ulid()",
+	"⟨my-table⟩:1",
+	"`my-table`:⟨a b⟩",
+	"person:1..10",
+	"person:1>..=10",
+	"person:..",
+	"person:..=['london', ..]",
+	"temperature:['london', d'2024-01-01T01:00:00.5+01:00', NONE, NULL, ..]",
+	"person:[{a: 1.5e3, 'b c': [true, -0.0]}, city:⟨new york⟩, u'0190d7fc-ca2d-7c87-9a1c-5a6e6bdcd1ad', \"q\\n\"]",
+	"person:[rand(), 1]",
+	"t:[math::inf, math::neg_inf]",
+	"price:['eur', 12.50dec, -1e-3dec]",
+	"0:[0e1dec]",
+	"0:[10e1dec]",
+	"retention:['logs', 1y2w3d4h5m6s7ms8µs9ns, 90us]",
+	"0:[1us]",
+	"t:[90µs]",
+	"audit:[d'2024-01-02', d\"2024-01-02T03:04:05.000000001-07:00\"]",
+	"person:[]",
+	`"person:tobie"`,
+	"r'person:tobie'",
+}
+
+func TestParseID(t *testing.T) {
+	u := uuid.Must(uuid.FromString("0190d7fc-ca2d-7c87-9a1c-5a6e6bdcd1ad"))
+	for _, c := range []struct {
+		in   string
+		want st.SurrealDBRecordID
+	}{
+		{"person:tobie", st.StringID{Table: "person", Thing: "tobie"}},
+		{"person:⟨tobie-the-great⟩", st.StringID{Table: "person", Thing: "tobie-the-great"}},
+		{`person:⟨esc\⟩aped⟩`, st.StringID{Table: "person", Thing: "esc⟩aped"}},
+		{"person:`tobie`", st.StringID{Table: "person", Thing: "tobie"}},
+		{"person:123", st.IntID{Table: "person", Thing: 123}},
+		{"person:⟨123⟩", st.StringID{Table: "person", Thing: "123"}},
+		{"person:1.5", st.FloatID{Table: "person", Thing: 1.5}},
+		{"person:u'0190d7fc-ca2d-7c87-9a1c-5a6e6bdcd1ad'", st.UUIDID{Table: "person", Thing: u}},
+		{"person:⟨0190d7fc-ca2d-7c87-9a1c-5a6e6bdcd1ad⟩", st.UUIDID{Table: "person", Thing: u}},
+		{"person:01ARZ3NDEKTSV4RRFFQ69G5FAV", st.ULIDID{Table: "person", Thing: ulid.MustParse("01ARZ3NDEKTSV4RRFFQ69G5FAV")}},
+		{"person:[1, 2]", st.ArrayID{Table: "person", Thing: []any{int64(1), int64(2)}}},
+		{"temperature:['london', d'2024-01-01T01:00:00+01:00', NONE, ..]", st.ArrayID{
+			Table: "temperature",
+			Thing: []any{"london", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), st.None, st.Unbounded},
+		}},
+		{"person:[{a: 1.5, 'b c': [true, NULL]}, city:⟨new york⟩]", st.ArrayID{
+			Table: "person",
+			Thing: []any{
+				map[string]any{"a": 1.5, "b c": []any{true, nil}},
+				st.StringID{Table: "city", Thing: "new york"},
+			},
+		}},
+		{"person:[rand()]", st.ObjectID{Table: "person", Thing: gjson.Parse("[rand()]")}},
+		{"retention:['logs', 90µs, 1us]", st.ArrayID{
+			Table: "retention",
+			Thing: []any{"logs", st.NewDuration(0, 90_000), st.NewDuration(0, 1_000)},
+		}},
+		{"person:ulid()", st.AutoID{Table: "person", Thing: st.AutoIDULID}},
+		{"⟨my-table⟩:1", st.IntID{Table: "my-table", Thing: 1}},
+		{`"person:tobie"`, st.StringID{Table: "person", Thing: "tobie"}},
+		{"person:1>..=10", st.RangeID{
+			Table: "person",
+			Begin: st.Exclusive(int64(1)),
+			End:   st.Inclusive(int64(10)),
+		}},
+		{"person:..['a', 1]", st.RangeID{Table: "person", End: st.Exclusive([]any{"a", int64(1)})}},
+		{"temperature:['london', NONE]..=['london', ..]", st.PrefixRange("temperature", "london")},
+	} {
+		got, err := st.ParseID(c.in)
+		if err != nil {
+			t.Errorf("%s: %s", c.in, err)
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %#v", c.in, got)
+		}
+	}
+
+	for _, bad := range []string{"", "person", ":1", "person:", "person:⟨open", "person:[1, 2", "person:1 2", "person:>..1", "person:1..="} {
+		if id, err := st.ParseID(bad); err == nil {
+			t.Errorf("%q parsed as %#v", bad, id)
+		}
+	}
+}
+
+// TableOf reads tables as ParseID does, and QuoteTable writes them back.
+func TestTableOf(t *testing.T) {
+	for id, want := range map[string]string{
+		"person:tobie":   "person",
+		"⟨my-table⟩:1":   "my-table",
+		"⟨a\\⟩b⟩:1":      "a⟩b",
+		"⟨a\\\\b⟩:1":     `a\b`,
+		"`a:b`:[1, 2]":   "a:b",
+		"person:⟨a:b⟩..": "person",
+	} {
+		table, ok := st.TableOf(id)
+		if !ok || table != want {
+			t.Errorf("%s: got %q, %v", id, table, ok)
+			continue
+		}
+		back, err := st.ParseID(st.QuoteTable(table) + ":1")
+		if err != nil || back.(st.IntID).Table != table {
+			t.Errorf("%s: %s read back as %#v: %v", id, st.QuoteTable(table), back, err)
+		}
+	}
+	for _, bad := range []string{"", "person", "⟨person:1", "`a"} {
+		if table, ok := st.TableOf(bad); ok {
+			t.Errorf("%q has table %q", bad, table)
+		}
+	}
+}
+
+func TestSurrealString(t *testing.T) {
+	for _, c := range []struct {
+		id   st.SurrealDBRecordID
+		want string
+	}{
+		{st.StringID{Table: "person", Thing: "tobie"}, "person:tobie"},
+		{st.StringID{Table: "person", Thing: "a b"}, "person:⟨a b⟩"},
+		{st.StringID{Table: "person", Thing: "123"}, "person:⟨123⟩"},
+		{st.StringID{Table: "my-table", Thing: "x"}, "⟨my-table⟩:x"},
+		{st.FloatID{Table: "person", Thing: 2}, "person:2.0"},
+		{st.ULIDID{Table: "person", Thing: ulid.MustParse("01ARZ3NDEKTSV4RRFFQ69G5FAV")}, "person:01ARZ3NDEKTSV4RRFFQ69G5FAV"},
+		{st.NewArrayID("temperature", "it's", 2.0, time.Date(2024, 1, 1, 1, 0, 0, 0, time.FixedZone("", 3600))), "temperature:['it\\'s', 2.0, d'2024-01-01T00:00:00Z']"},
+		{st.NewArrayID("t", st.NewDuration(0, 1_000), st.NewDuration(1, 2)), "t:[1µs, 1s2ns]"},
+		{st.PrefixBetween("temperature", []any{"london"}, 1, 5), "temperature:['london', 1]..['london', 5]"},
+		{st.RangeID{Table: "person", Begin: st.Exclusive("a b")}, "person:⟨a b⟩>.."},
+	} {
+		if got := c.id.SurrealString(); got != c.want {
+			t.Errorf("%#v: got %s", c.id, got)
+		}
+	}
+}
+
+// Infinities read back as themselves; NaN, which is no key, not at all.
+func TestSurrealStringInf(t *testing.T) {
+	for _, f := range []float64{math.Inf(1), math.Inf(-1)} {
+		id := st.NewArrayID("t", f)
+		if again, err := st.ParseID(id.SurrealString()); err != nil || !reflect.DeepEqual(again, id) {
+			t.Errorf("%s read back as %#v, %v", id.SurrealString(), again, err)
+		}
+	}
+	out := st.NewArrayID("t", math.NaN()).SurrealString()
+	if id, err := st.ParseID(out); err == nil {
+		t.Errorf("%s read back as %#v", out, id)
+	}
+}
+
+// Every ID ParseID returns must come back the same from its SurrealString.
+func FuzzParseID(f *testing.F) {
+	for _, seed := range idSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		id, err := st.ParseID(in)
+		if err != nil {
+			return
+		}
+		out := id.SurrealString()
+		again, err := st.ParseID(out)
+		if err != nil {
+			t.Fatalf("%q gave %q, which does not parse: %s", in, out, err)
+		}
+		if !reflect.DeepEqual(id, again) {
+			t.Fatalf("%q gave %#v as %q, which parses as %#v", in, id, out, again)
+		}
+		if again.SurrealString() != out {
+			t.Fatalf("%q is written as %q, then as %q", in, out, again.SurrealString())
+		}
+	})
+}
+
+type users struct{}
+
+func (users) TableName() string { return "users" }
+
+type posts struct{}
+
+func (posts) TableName() string { return "posts" }
+
+func TestID(t *testing.T) {
+	var post struct {
+		Author st.ID[users] `json:"author"`
+		Editor st.ID[users] `json:"editor"`
+	}
+	if err := json.Unmarshal([]byte(`{"author": "users:tobie", "editor": null}`), &post); err != nil {
+		t.Fatal(err)
+	}
+	if post.Author.Key() != "tobie" || !post.Editor.IsZero() {
+		t.Errorf("decoded %#v", post)
+	}
+	if err := json.Unmarshal([]byte(`{"author": "posts:1"}`), &post); err == nil {
+		t.Error("a posts ID was taken for a users ID")
+	}
+	out, err := json.Marshal(post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"author":"users:tobie","editor":null}` {
+		t.Errorf("encoded %s", out)
+	}
+
+	id := st.MustID[posts](42)
+	if id.SurrealString() != "posts:42" || id.Key() != int64(42) {
+		t.Errorf("built %s", id.SurrealString())
+	}
+	if _, err := st.IDFrom[users](id); err == nil {
+		t.Error("converted a posts ID to a users ID")
+	}
+	if back, err := st.IDFrom[posts](id.Record()); err != nil || back != id {
+		t.Errorf("converted back to %v: %v", back, err)
+	}
+	if _, err := st.NewID[posts](true); err == nil {
+		t.Error("a bool became a key")
+	}
+}
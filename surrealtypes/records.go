@@ -9,8 +9,6 @@ import (
 	"github.com/tidwall/gjson"
 )
 
-var recordsTkemba = localKemba.Extend("Records[T]")
-
 type Records[T any] struct {
 	inner       []*Record[T]
 	hasAnything bool
@@ -24,8 +22,6 @@ var _ sql.Scanner = (*Records[T])(nil)
 */
 
 func NewRecords[T any](obj []T) Records[T] {
-	k := recordsTkemba.Extend("NewRecords[T]")
-	k.Printf("Making Record[T]s off of %T", obj)
 	out := make([]*Record[T], len(obj))
 	for _, o := range obj {
 		r := NewRecord(o)
@@ -43,7 +39,6 @@ func (r *Records[T]) Len() int {
 }
 
 func (r *Records[T]) UnmarshalJSON(b []byte) error {
-	k := recordsTkemba.Extend("UnmarshalJSON")
 	data := gjson.ParseBytes(b)
 
 	if !data.IsArray() {
@@ -51,42 +46,32 @@ func (r *Records[T]) UnmarshalJSON(b []byte) error {
 	}
 
 	if len(data.Array()) > 0 {
-		k.Log("array has more than one object")
 		r.hasAnything = true
 		for _, value := range data.Array() {
-			k := k.Extend("ForEach")
-			k.Log("processing", value)
 			one := new(Record[T])
 			if err := json.Unmarshal([]byte(value.Raw), one); err != nil {
-				k.Log("Error caused with this: ", value.Raw)
 				return err
 			}
 			r.inner = append(r.inner, one)
 		}
 	} else {
-		k.Log("array has no values")
 		r.hasAnything = false
 	}
 	return nil
 }
 
 func (r *Records[T]) MarshalJSON() ([]byte, error) {
-	k := recordsTkemba.Extend("MarshalJSON")
 	if r.hasAnything {
 		// BUG(IP): %T may result in pretty.formatter from github.com/kr/pretty ... fml.
-		k.Log(fmt.Sprintf("Marshalling Records[T] -> %T", r.inner), r.inner)
 		return json.Marshal(r.inner)
 	} else {
-		k.Log("Nothing to unmarshal, returning empty array")
 		return []byte("[]"), nil
 	}
 }
 
 func (r *Records[T]) Scan(src any) error {
-	k := recordsTkemba.Extend("Scan")
 	switch data := src.(type) {
 	case []byte:
-		k.Log("Found []byte")
 		return r.UnmarshalJSON(data)
 	default:
 		return fmt.Errorf("input must be []byte, found %T", src)
@@ -94,7 +79,5 @@ func (r *Records[T]) Scan(src any) error {
 }
 
 func (r *Records[T]) Value() (driver.Value, error) {
-	k := recordsTkemba.Extend("Value")
-	k.Log("-> MarshalJSON")
 	return r.MarshalJSON()
 }
@@ -0,0 +1,534 @@
+package surrealtypes
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/gofrs/uuid/v5"
+	"github.com/oklog/ulid/v2"
+	"github.com/tidwall/gjson"
+)
+
+// Unbounded is the `..` in an array key: it sorts after every value, like
+// None sorts before every value. Together, they make ranges over all keys
+// starting with some values, see PrefixRange.
+var Unbounded = unbounded{}
+
+type unbounded struct{}
+
+// NaN, which formatFloat writes for lack of a literal: it equals nothing,
+// not even itself, so it can be no key.
+var errNaN = errors.New("NaN can not be in a record key")
+
+func (unbounded) String() string {
+	return ".."
+}
+
+// The values SurrealQL literals in record keys are read as: nil (NULL),
+// None, Unbounded, bool, int64, float64, Decimal, Duration, string,
+// time.Time (in UTC), uuid.UUID, SurrealDBRecordID, []any and
+// map[string]any.
+func (p *idParser) value() (any, error) {
+	p.skipSpace()
+	rest := p.rest()
+	if rest == "" {
+		return nil, errors.New("missing value")
+	}
+	switch c := rest[0]; {
+	case c == '[':
+		return p.array()
+	case c == '{':
+		return p.object()
+	case c == '\'' || c == '"':
+		return p.quoted()
+	case strings.HasPrefix(rest, ".."):
+		p.pos += 2
+		return Unbounded, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.number()
+	case len(rest) > 1 && (rest[1] == '\'' || rest[1] == '"') && strings.IndexByte("sdur", c) >= 0:
+		p.pos++
+		s, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		switch c {
+		case 'd':
+			t, err := ParseDateTime(s)
+			return t.Time, err
+		case 'u':
+			return uuid.FromString(s)
+		case 'r':
+			id, err := ParseID(s)
+			if _, ok := id.(RangeID); ok {
+				return nil, errors.New("a range is not a record")
+			}
+			return id, err
+		}
+		return s, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.in) && isIdentChar(p.in[p.pos]) {
+		p.pos++
+	}
+	word := p.in[start:p.pos]
+	if strings.EqualFold(word, "math") {
+		for c, f := range map[string]float64{"::inf": math.Inf(1), "::neg_inf": math.Inf(-1)} {
+			if rest := p.rest(); strings.HasPrefix(rest, c) && (len(rest) == len(c) || !isIdentChar(rest[len(c)])) {
+				p.pos += len(c)
+				return f, nil
+			}
+		}
+	}
+	if strings.HasPrefix(p.rest(), ":") || (word == "" && strings.HasPrefix(p.rest(), string(SRIDOpen))) {
+		// A record ID.
+		p.pos = start
+		table, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(p.rest(), ":") {
+			return nil, errors.New("no ':' after the table")
+		}
+		p.pos++
+		return p.key(table)
+	}
+	switch strings.ToUpper(word) {
+	case "NONE":
+		return None, nil
+	case "NULL":
+		return nil, nil
+	case "TRUE":
+		return true, nil
+	case "FALSE":
+		return false, nil
+	case "NAN":
+		return nil, errNaN
+	}
+	return nil, fmt.Errorf("unexpected %q", rest)
+}
+
+func (p *idParser) skipSpace() {
+	for p.pos < len(p.in) && strings.IndexByte(" \t\r\n", p.in[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// Elements of an array or entries of an object, up to closing.
+func (p *idParser) list(closing byte, entry func() error) error {
+	p.pos++
+	for {
+		p.skipSpace()
+		if strings.HasPrefix(p.rest(), string(closing)) {
+			p.pos++
+			return nil
+		}
+		if err := entry(); err != nil {
+			return err
+		}
+		p.skipSpace()
+		if strings.HasPrefix(p.rest(), ",") {
+			p.pos++
+		} else if !strings.HasPrefix(p.rest(), string(closing)) {
+			return fmt.Errorf("expected ',' or '%c'", closing)
+		}
+	}
+}
+
+func (p *idParser) array() ([]any, error) {
+	out := []any{}
+	err := p.list(']', func() error {
+		v, err := p.value()
+		out = append(out, v)
+		return err
+	})
+	return out, err
+}
+
+func (p *idParser) object() (map[string]any, error) {
+	out := map[string]any{}
+	err := p.list('}', func() error {
+		var key string
+		if rest := p.rest(); rest != "" && (rest[0] == '\'' || rest[0] == '"') {
+			var err error
+			if key, err = p.quoted(); err != nil {
+				return err
+			}
+		} else {
+			start := p.pos
+			for p.pos < len(p.in) && isIdentChar(p.in[p.pos]) {
+				p.pos++
+			}
+			if key = p.in[start:p.pos]; key == "" {
+				return errors.New("missing key")
+			}
+		}
+		p.skipSpace()
+		if !strings.HasPrefix(p.rest(), ":") {
+			return errors.New("expected ':'")
+		}
+		p.pos++
+		v, err := p.value()
+		out[key] = v
+		return err
+	})
+	return out, err
+}
+
+// A string in single or double quotes.
+func (p *idParser) quoted() (string, error) {
+	quote := p.in[p.pos]
+	p.pos++
+	out := strings.Builder{}
+	for p.pos < len(p.in) {
+		c := p.in[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			return out.String(), nil
+		case c == '\\' && p.pos < len(p.in):
+			e := p.in[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				out.WriteByte('\n')
+			case 'r':
+				out.WriteByte('\r')
+			case 't':
+				out.WriteByte('\t')
+			case '0':
+				out.WriteByte(0)
+			default:
+				out.WriteByte(e)
+			}
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return "", errors.New("unterminated string")
+}
+
+func (p *idParser) number() (any, error) {
+	start := p.pos
+	if strings.HasPrefix(p.rest(), "-") {
+		p.pos++
+	}
+	digits := func() int {
+		n := 0
+		for p.pos < len(p.in) && p.in[p.pos] >= '0' && p.in[p.pos] <= '9' {
+			p.pos++
+			n++
+		}
+		return n
+	}
+	if digits() == 0 {
+		return nil, errors.New("malformed number")
+	}
+	float := false
+	if strings.HasPrefix(p.rest(), ".") && !strings.HasPrefix(p.rest(), "..") {
+		p.pos++
+		if digits() == 0 {
+			return nil, errors.New("malformed number")
+		}
+		float = true
+	}
+	if rest := p.rest(); rest != "" && (rest[0] == 'e' || rest[0] == 'E') {
+		p.pos++
+		if rest := p.rest(); rest != "" && (rest[0] == '+' || rest[0] == '-') {
+			p.pos++
+		}
+		if digits() == 0 {
+			return nil, errors.New("malformed number")
+		}
+		float = true
+	}
+	text := p.in[start:p.pos]
+	if rest := p.rest(); strings.HasPrefix(rest, "dec") && (len(rest) == 3 || !isIdentChar(rest[3])) {
+		p.pos += 3
+		return ParseDecimal(text)
+	}
+	if _, ok := durationUnit(p.rest()); ok && !float && text[0] != '-' {
+		for p.pos < len(p.in) {
+			if strings.HasPrefix(p.rest(), "µ") {
+				p.pos += len("µ")
+			} else if isIdentChar(p.in[p.pos]) {
+				p.pos++
+			} else {
+				break
+			}
+		}
+		return ParseDuration(p.in[start:p.pos])
+	}
+	if p.pos < len(p.in) && isIdentChar(p.in[p.pos]) {
+		// Suffixes like 1f are not supported.
+		return nil, fmt.Errorf("unexpected %q after %s", p.rest(), text)
+	}
+	if !float {
+		return strconv.ParseInt(text, 10, 64)
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Writes v as a SurrealQL literal; see idParser.value for the types that
+// read back the same. Other values are written as JSON.
+func formatValue(out *strings.Builder, v any) {
+	switch v := v.(type) {
+	case nil:
+		out.WriteString("NULL")
+	case none:
+		out.WriteString("NONE")
+	case unbounded:
+		out.WriteString("..")
+	case bool:
+		out.WriteString(strconv.FormatBool(v))
+	case int:
+		out.WriteString(strconv.Itoa(v))
+	case int64:
+		out.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		out.WriteString(formatFloat(v))
+	case json.Number:
+		out.WriteString(v.String())
+	case string:
+		formatQuoted(out, v)
+	case time.Time:
+		out.WriteByte('d')
+		formatQuoted(out, v.UTC().Format(time.RFC3339Nano))
+	case DateTime:
+		formatValue(out, v.Time)
+	case Decimal:
+		b, _ := v.MarshalSurreal()
+		out.Write(b)
+	case Duration:
+		out.WriteString(v.String())
+	case uuid.UUID:
+		out.WriteByte('u')
+		formatQuoted(out, v.String())
+	case ulid.ULID:
+		formatQuoted(out, v.String())
+	case SurrealDBRecordID:
+		out.WriteString(v.SurrealString())
+	case []any:
+		out.WriteByte('[')
+		for i, e := range v {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			formatValue(out, e)
+		}
+		out.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			formatKey(out, k)
+			out.WriteString(": ")
+			formatValue(out, v[k])
+		}
+		out.WriteByte('}')
+	default:
+		if elems, ok := anySlice(v); ok {
+			formatValue(out, elems)
+			return
+		}
+		if n, ok := anyInt(v); ok {
+			out.WriteString(strconv.FormatInt(n, 10))
+			return
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			out.WriteString("NULL")
+			return
+		}
+		out.Write(b)
+	}
+}
+
+// An object key, quoted unless it is an identifier.
+func formatKey(out *strings.Builder, k string) {
+	if k != "" && strings.IndexFunc(k, func(r rune) bool { return r > 0x7f || !isIdentChar(byte(r)) }) < 0 {
+		out.WriteString(k)
+	} else {
+		formatQuoted(out, k)
+	}
+}
+
+// A float that reads back as one, i.e. 2.0 rather than 2. Infinities are
+// the constants math::inf and math::neg_inf; NaN, which has no literal, is
+// written as NaN and fails to read back.
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "math::inf"
+	case math.IsInf(f, -1):
+		return "math::neg_inf"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eEn") {
+		s += ".0"
+	}
+	return s
+}
+
+// Binds v like BindParam, but as an expression over variables added to
+// vars: name for v itself, or name_0, name_1… for the elements of arrays
+// and objects holding values JSON has no type for, which are built from
+// their elements, i.e. [$.k_0, <datetime>$.k_1].
+func bindValue(v any, vars map[string]any, name string) string {
+	if isPlain(v) {
+		vars[name] = v
+		return "$." + name
+	}
+	switch v := v.(type) {
+	case unbounded:
+		return ".."
+	case float64:
+		// JSON has no infinities either.
+		return formatFloat(v)
+	case gjson.Result:
+		return bindValue(v.Value(), vars, name)
+	case []any:
+		return bindElems(v, vars, name)
+	case map[string]any:
+		return bindEntries(v, vars, name)
+	}
+	if elems, ok := anySlice(v); ok {
+		return bindElems(elems, vars, name)
+	}
+	expr, value := BindParam(v)
+	vars[name] = value
+	return strings.ReplaceAll(expr, "$", "$."+name)
+}
+
+// An array literal of elems, bound by bindValue.
+func bindElems(elems []any, vars map[string]any, name string) string {
+	out := strings.Builder{}
+	out.WriteByte('[')
+	for i, e := range elems {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(bindValue(e, vars, name+"_"+strconv.Itoa(i)))
+	}
+	out.WriteByte(']')
+	return out.String()
+}
+
+// An object literal of entries, bound by bindValue. The keys are written
+// into it, quoted where needed: SurrealQL has no variable keys.
+func bindEntries(entries map[string]any, vars map[string]any, name string) string {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := strings.Builder{}
+	out.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		formatKey(&out, k)
+		out.WriteString(": ")
+		out.WriteString(bindValue(entries[k], vars, name+"_"+strconv.Itoa(i)))
+	}
+	out.WriteByte('}')
+	return out.String()
+}
+
+// Whether v is bound as is, arrays and objects included: it holds nothing
+// BindParam casts.
+func isPlain(v any) bool {
+	switch v := v.(type) {
+	case unbounded:
+		return false
+	case float64:
+		return !math.IsInf(v, 0)
+	case gjson.Result:
+		return true
+	case []any:
+		for _, e := range v {
+			if !isPlain(e) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		for _, e := range v {
+			if !isPlain(e) {
+				return false
+			}
+		}
+		return true
+	}
+	if elems, ok := anySlice(v); ok {
+		return isPlain(elems)
+	}
+	expr, _ := BindParam(v)
+	return expr == "$"
+}
+
+func formatQuoted(out *strings.Builder, s string) {
+	out.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\'', '\\':
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\r':
+			out.WriteString(`\r`)
+		case '\t':
+			out.WriteString(`\t`)
+		case 0:
+			out.WriteString(`\0`)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	out.WriteByte('\'')
+}
+
+// Slices and arrays other than []byte, as []any.
+func anySlice(v any) ([]any, bool) {
+	rv := reflect.ValueOf(v)
+	if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out, true
+}
+
+func anyInt(v any) (int64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return int64(rv.Uint()), true
+	}
+	return 0, false
+}
@@ -1,6 +1,54 @@
 package surrealtypes
 
+import (
+	"reflect"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
 type SurrealMarshalable interface {
 	MarshalSurreal() ([]byte, error)
 	//UnmarshalSurreal([]byte) error
 }
+
+// SurrealParam is implemented by values that are bound to a query as a
+// SurrealDB type of their own. JSON, which queries are sent as, has no
+// datetimes, durations or record links; such values are sent as their JSON
+// form and turned back into their type by a SurrealQL expression, in which
+// $ stands for the variable, i.e. `<datetime>$`.
+type SurrealParam interface {
+	SurrealParam() (expr string, value any)
+}
+
+// BindParam tells how v is bound to a query: a SurrealParam as it says,
+// time.Time, time.Duration and uuid.UUID as a datetime, a duration and a
+// uuid, and anything else as is, with the expression "$". Geometries need
+// nothing extra; SurrealDB turns GeoJSON objects into geometries by itself.
+func BindParam(v any) (expr string, value any) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return "$", nil
+	}
+	switch v := v.(type) {
+	case SurrealParam:
+		return v.SurrealParam()
+	case time.Time:
+		return DateTime{Time: v}.SurrealParam()
+	case *time.Time:
+		return DateTime{Time: *v}.SurrealParam()
+	case time.Duration:
+		return durationParam(v)
+	case *time.Duration:
+		return durationParam(*v)
+	case uuid.UUID:
+		return "<uuid>$", v.String()
+	case *uuid.UUID:
+		return "<uuid>$", v.String()
+	}
+	return "$", v
+}
+
+// The expression and value binding a record ID; thing is its JSON form.
+func recordParam(table string, thing any) (string, any) {
+	return "type::thing($.tb, $.id)", map[string]any{"tb": table, "id": thing}
+}
@@ -0,0 +1,79 @@
+package surrealdbdriver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Transport carries RPC messages between a connection and SurrealDB. Each
+// message is one JSON document. Writes are serialized by the connection;
+// reads only ever happen on the connection's reader.
+//
+// The default transport is a WebSocket. Others can be plugged in with
+// WithTransport, or wrapped around the default one with WithTransportWrapper
+// (i.e. to record the traffic).
+type Transport interface {
+	WriteMessage(msg []byte) error
+	ReadMessage() ([]byte, error)
+	Close() error
+}
+
+// TransportFunc dials a Transport.
+type TransportFunc func(ctx context.Context) (Transport, error)
+
+// The WebSocket transport.
+type wsTransport struct {
+	conn         *websocket.Conn
+	writeTimeout time.Duration
+}
+
+func (t *wsTransport) WriteMessage(msg []byte) error {
+	if t.writeTimeout > 0 {
+		t.conn.SetWriteDeadline(time.Now().Add(t.writeTimeout))
+		defer t.conn.SetWriteDeadline(time.Time{})
+	}
+	return t.conn.WriteMessage(websocket.TextMessage, msg)
+}
+
+func (t *wsTransport) ReadMessage() ([]byte, error) {
+	for {
+		mtyp, msg, err := t.conn.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		if mtyp == websocket.BinaryMessage || mtyp == websocket.TextMessage {
+			return msg, nil
+		}
+	}
+}
+
+func (t *wsTransport) Close() error {
+	return t.conn.Close()
+}
+
+// WithTransport replaces dialing a WebSocket with dial. Pings, heartbeats and
+// close frames are WebSocket features and are skipped for such transports.
+func WithTransport(dial TransportFunc) Option {
+	return func(c *SurrealConnector) error {
+		if dial == nil {
+			return errors.New("transport dialer must not be nil")
+		}
+		c.dialTransport = dial
+		return nil
+	}
+}
+
+// WithTransportWrapper wraps every connection's transport, i.e. to observe or
+// record the traffic. Wrappers are applied in the order they were given.
+func WithTransportWrapper(wrap func(Transport) Transport) Option {
+	return func(c *SurrealConnector) error {
+		if wrap == nil {
+			return errors.New("transport wrapper must not be nil")
+		}
+		c.transportWrappers = append(c.transportWrappers, wrap)
+		return nil
+	}
+}
@@ -2,45 +2,17 @@ package surrealdbdriver
 
 import (
 	"database/sql/driver"
+	"fmt"
 	"hash/fnv"
-	"os"
+	"reflect"
 	"strconv"
 	"time"
 
 	"github.com/IngwiePhoenix/surrealdb-driver/api"
 	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
-	"github.com/clok/kemba"
 	"github.com/tidwall/gjson"
-	"github.com/ztrue/tracerr"
 )
 
-type Debugger struct {
-	k *kemba.Kemba
-}
-
-func (d *Debugger) Debug(e error) bool {
-	var skip = true
-	if _, ok := os.LookupEnv("KEMBA"); ok {
-		skip = false
-	} else if _, ok := os.LookupEnv("DEBUG"); ok {
-		skip = false
-	}
-
-	if skip {
-		return e != nil
-	}
-
-	if e != nil {
-		d.k.Log("ERROR : " + tracerr.SprintSource(e))
-		return true
-	}
-	return false
-}
-
-func makeErrorLogger(k *kemba.Kemba) *Debugger {
-	return &Debugger{k: k}
-}
-
 // Basically a ripoff from: https://github.com/go-sql-driver/mysql/blob/341a5a5246835b2ac4b8d36bb12a9dfad70663f4/statement.go#L143
 // Only the variable names were slightly changed but...that's that.
 // Purpose of this method is to convert the value to something sensible, and error out
@@ -52,13 +24,49 @@ func makeErrorLogger(k *kemba.Kemba) *Debugger {
 // > If ErrSkip is returned the column converter error checking path is used for the argument.
 // > Drivers may wish to return ErrSkip after they have exhausted their own special cases.
 // (via: https://pkg.go.dev/database/sql/driver#NamedValueChecker)
+//
+// Values with a SurrealDB type of their own - times, durations, decimals,
+// UUIDs and record IDs - are kept as such, to be bound with their type when
+// the query is sent (see bindParams).
+//
+// Values JSON can't carry - channels, funcs, complex numbers - and negative
+// durations, which SurrealDB has none of, are rejected here rather than by
+// the server.
 func checkNamedValue(value any) (driver.Value, error) {
-	if s, ok := value.(st.SurrealDBRecordID); ok {
-		return s.SurrealString(), nil
-	} else if t, ok := value.(time.Time); ok {
-		return `d'` + t.Format(time.RFC3339) + `'`, nil
+	switch v := value.(type) {
+	case time.Duration:
+		if _, err := st.DurationOf(v); err != nil {
+			return nil, err
+		}
+	case *time.Duration:
+		if v != nil {
+			if _, err := st.DurationOf(*v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if value != nil && !bindableType(reflect.TypeOf(value), map[reflect.Type]bool{}) {
+		return nil, fmt.Errorf("cannot bind a %T as a query parameter", value)
+	}
+	return encodeParam(value), nil
+}
+
+// Whether JSON can carry values of t, as far as its type tells; fields of
+// structs are left to the encoder.
+func bindableType(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return true
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return false
+	case reflect.Map:
+		return bindableType(t.Key(), seen) && bindableType(t.Elem(), seen)
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return bindableType(t.Elem(), seen)
 	}
-	return value, nil
+	return true
 }
 
 func validateResponse(method api.APIMethod, data []byte) (*api.Response, error) {
@@ -7,22 +7,19 @@ import (
 	"strings"
 	"time"
 
+	st "github.com/IngwiePhoenix/surrealdb-driver/surrealtypes"
 	"github.com/tidwall/gjson"
 )
 
 func gjsonNumberToDriverValue(input gjson.Result) (driver.Value, error) {
-	k := localKemba.Extend("gjsonNumberToDriverValue")
-
 	str := input.String()
 	if strings.Contains(str, ".") || strings.ContainsAny(str, "eE") {
-		k.Printf("attempting to parse as float (%s)\n", str)
 		if f, err := strconv.ParseFloat(str, 64); err == nil {
 			return f, nil
 		} else {
 			return nil, err
 		}
 	} else {
-		k.Printf("attempting to parse as int (%s)\n", str)
 		// Try parsing as int
 		if i, err := strconv.ParseInt(str, 10, 64); err == nil {
 			return i, nil
@@ -32,41 +29,67 @@ func gjsonNumberToDriverValue(input gjson.Result) (driver.Value, error) {
 	}
 
 	// Fallback: parse as float (shouldn't reach here under normal circumstances)
-	k.Printf("falling through! (%s)\n", str)
 	f, err := strconv.ParseFloat(str, 64)
 	return f, err
 }
 
 func convertValue(input gjson.Result) (driver.Value, error) {
-	k := localKemba.Extend("convertValue")
 	switch input.Type {
 	case gjson.Null:
-		k.Log("Converting NULL")
 		return nil, nil
 	case gjson.JSON:
-		k.Log("Converting JSON")
 		return []byte(input.Raw), nil
 	case gjson.True, gjson.False:
-		k.Log("Converting boolean")
 		return input.Bool(), nil
 	case gjson.Number:
-		k.Log("Converting number")
 		return gjsonNumberToDriverValue(input)
 	case gjson.String:
-		k.Log("Converting string")
-		k.Log("...is it a time, duration or just a string?")
-		if t, err := time.Parse(time.RFC3339Nano, input.String()); err == nil {
-			k.Printf("it's time: %v\n", t)
-			return t, nil
-		} else if t, err := time.ParseDuration(input.String()); err == nil {
-			k.Printf("it's duration: %v\n", t)
-			return t, nil
-		} else {
-			k.Printf("it's string: %v\n", input.String())
-			return input.String(), nil
-		}
+		return input.String(), nil
 	}
 	panic("convertValue: did fall through entirely")
 }
 
+// Like convertValue, but strings that look like a datetime or a duration
+// become one, see DecodeHeuristic.
+func sniffValue(input gjson.Result) (driver.Value, error) {
+	if input.Type != gjson.String {
+		return convertValue(input)
+	}
+	if t, err := time.Parse(time.RFC3339Nano, input.String()); err == nil {
+		return t, nil
+	} else if d, ok := parseDuration(input.String()); ok {
+		return d, nil
+	}
+	return input.String(), nil
+}
+
+// Like convertValue, but strings of a field of the given type in the schema
+// become that type, see DecodeSchema.
+func convertField(input gjson.Result, kind string) (driver.Value, error) {
+	if input.Type != gjson.String {
+		return convertValue(input)
+	}
+	switch kind {
+	case "datetime":
+		t, err := st.ParseDateTime(input.String())
+		return t.Time, err
+	case "duration":
+		if d, ok := parseDuration(input.String()); ok {
+			return d, nil
+		}
+		// Longer than a time.Duration; scan it into an st.Duration.
+	}
+	return input.String(), nil
+}
+
+// A duration as SurrealDB writes it, i.e. 1w2d, if it fits a time.Duration.
+func parseDuration(s string) (time.Duration, bool) {
+	parsed, err := st.ParseDuration(s)
+	if err != nil {
+		return 0, false
+	}
+	d, err := parsed.TimeDuration()
+	return d, err == nil
+}
+
 //func surrealizeValue(in any) driver.Value {}